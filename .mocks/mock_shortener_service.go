@@ -64,17 +64,17 @@ func (mr *MockDBMockRecorder) Get(arg0 interface{}) *gomock.Call {
 }
 
 // Hit mocks base method.
-func (m *MockDB) Hit(arg0 string) error {
+func (m *MockDB) Hit(arg0 string, arg1 model.Hit) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Hit", arg0)
+	ret := m.ctrl.Call(m, "Hit", arg0, arg1)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Hit indicates an expected call of Hit.
-func (mr *MockDBMockRecorder) Hit(arg0 interface{}) *gomock.Call {
+func (mr *MockDBMockRecorder) Hit(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hit", reflect.TypeOf((*MockDB)(nil).Hit), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Hit", reflect.TypeOf((*MockDB)(nil).Hit), arg0, arg1)
 }
 
 // Restore mocks base method.
@@ -102,3 +102,91 @@ func (mr *MockDBMockRecorder) Set(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockDB)(nil).Set), arg0, arg1)
 }
+
+// Update mocks base method.
+func (m *MockDB) Update(arg0 string, arg1 func(*model.RedirectionData) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockDBMockRecorder) Update(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockDB)(nil).Update), arg0, arg1)
+}
+
+// MockPolicy is a mock of Policy interface.
+type MockPolicy struct {
+	ctrl     *gomock.Controller
+	recorder *MockPolicyMockRecorder
+}
+
+// MockPolicyMockRecorder is the mock recorder for MockPolicy.
+type MockPolicyMockRecorder struct {
+	mock *MockPolicy
+}
+
+// NewMockPolicy creates a new mock instance.
+func NewMockPolicy(ctrl *gomock.Controller) *MockPolicy {
+	mock := &MockPolicy{ctrl: ctrl}
+	mock.recorder = &MockPolicyMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockPolicy) EXPECT() *MockPolicyMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockPolicy) Check(host string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", host)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockPolicyMockRecorder) Check(host interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockPolicy)(nil).Check), host)
+}
+
+// MockWordFilter is a mock of WordFilter interface.
+type MockWordFilter struct {
+	ctrl     *gomock.Controller
+	recorder *MockWordFilterMockRecorder
+}
+
+// MockWordFilterMockRecorder is the mock recorder for MockWordFilter.
+type MockWordFilterMockRecorder struct {
+	mock *MockWordFilter
+}
+
+// NewMockWordFilter creates a new mock instance.
+func NewMockWordFilter(ctrl *gomock.Controller) *MockWordFilter {
+	mock := &MockWordFilter{ctrl: ctrl}
+	mock.recorder = &MockWordFilterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWordFilter) EXPECT() *MockWordFilterMockRecorder {
+	return m.recorder
+}
+
+// Contains mocks base method.
+func (m *MockWordFilter) Contains(text string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Contains", text)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// Contains indicates an expected call of Contains.
+func (mr *MockWordFilterMockRecorder) Contains(text interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Contains", reflect.TypeOf((*MockWordFilter)(nil).Contains), text)
+}
@@ -6,7 +6,9 @@ package mocks
 
 import (
 	model "dh-url-shortener/internal/api/model"
+	net "net"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -35,10 +37,10 @@ func (m *MockShortenerService) EXPECT() *MockShortenerServiceMockRecorder {
 }
 
 // Expand mocks base method.
-func (m *MockShortenerService) Expand(arg0 string) (string, error) {
+func (m *MockShortenerService) Expand(arg0 model.ExpandRequest) (model.Redirection, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "Expand", arg0)
-	ret0, _ := ret[0].(string)
+	ret0, _ := ret[0].(model.Redirection)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
@@ -49,31 +51,238 @@ func (mr *MockShortenerServiceMockRecorder) Expand(arg0 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Expand", reflect.TypeOf((*MockShortenerService)(nil).Expand), arg0)
 }
 
+// History mocks base method.
+func (m *MockShortenerService) History(arg0, arg1, arg2 string) ([]model.Revision, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "History", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]model.Revision)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// History indicates an expected call of History.
+func (mr *MockShortenerServiceMockRecorder) History(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "History", reflect.TypeOf((*MockShortenerService)(nil).History), arg0, arg1, arg2)
+}
+
 // List mocks base method.
-func (m *MockShortenerService) List() []model.ListData {
+func (m *MockShortenerService) List(arg0 model.ListFilter) []model.ListData {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "List")
+	ret := m.ctrl.Call(m, "List", arg0)
 	ret0, _ := ret[0].([]model.ListData)
 	return ret0
 }
 
 // List indicates an expected call of List.
-func (mr *MockShortenerServiceMockRecorder) List() *gomock.Call {
+func (mr *MockShortenerServiceMockRecorder) List(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockShortenerService)(nil).List), arg0)
+}
+
+// Preview mocks base method.
+func (m *MockShortenerService) Preview(arg0, arg1 string) (model.Preview, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Preview", arg0, arg1)
+	ret0, _ := ret[0].(model.Preview)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Preview indicates an expected call of Preview.
+func (mr *MockShortenerServiceMockRecorder) Preview(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Preview", reflect.TypeOf((*MockShortenerService)(nil).Preview), arg0, arg1)
+}
+
+// RestoreRevision mocks base method.
+func (m *MockShortenerService) RestoreRevision(arg0, arg1, arg2 string, arg3 int, arg4 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreRevision", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RestoreRevision indicates an expected call of RestoreRevision.
+func (mr *MockShortenerServiceMockRecorder) RestoreRevision(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockShortenerService)(nil).List))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreRevision", reflect.TypeOf((*MockShortenerService)(nil).RestoreRevision), arg0, arg1, arg2, arg3, arg4)
 }
 
 // Shorten mocks base method.
-func (m *MockShortenerService) Shorten(arg0 string) (string, error) {
+func (m *MockShortenerService) Shorten(arg0 string, arg1 model.LinkOptions) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Shorten", arg0)
+	ret := m.ctrl.Call(m, "Shorten", arg0, arg1)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Shorten indicates an expected call of Shorten.
-func (mr *MockShortenerServiceMockRecorder) Shorten(arg0 interface{}) *gomock.Call {
+func (mr *MockShortenerServiceMockRecorder) Shorten(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shorten", reflect.TypeOf((*MockShortenerService)(nil).Shorten), arg0, arg1)
+}
+
+// Stats mocks base method.
+func (m *MockShortenerService) Stats(arg0, arg1, arg2 string, arg3 model.StatsQuery) (model.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Stats", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(model.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Stats indicates an expected call of Stats.
+func (mr *MockShortenerServiceMockRecorder) Stats(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Stats", reflect.TypeOf((*MockShortenerService)(nil).Stats), arg0, arg1, arg2, arg3)
+}
+
+// Subscribe mocks base method.
+func (m *MockShortenerService) Subscribe(arg0 model.EventFilter) (<-chan model.HitEvent, func()) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", arg0)
+	ret0, _ := ret[0].(<-chan model.HitEvent)
+	ret1, _ := ret[1].(func())
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockShortenerServiceMockRecorder) Subscribe(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockShortenerService)(nil).Subscribe), arg0)
+}
+
+// Suggest mocks base method.
+func (m *MockShortenerService) Suggest(arg0, arg1 string) (string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suggest", arg0, arg1)
+	ret0, _ := ret[0].(string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Suggest indicates an expected call of Suggest.
+func (mr *MockShortenerServiceMockRecorder) Suggest(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suggest", reflect.TypeOf((*MockShortenerService)(nil).Suggest), arg0, arg1)
+}
+
+// Top mocks base method.
+func (m *MockShortenerService) Top(arg0 string, arg1 int, arg2 time.Duration) ([]model.TopLink, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Top", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]model.TopLink)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Top indicates an expected call of Top.
+func (mr *MockShortenerServiceMockRecorder) Top(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Top", reflect.TypeOf((*MockShortenerService)(nil).Top), arg0, arg1, arg2)
+}
+
+// Unlock mocks base method.
+func (m *MockShortenerService) Unlock(arg0 model.ExpandRequest, arg1 string) (model.Redirection, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unlock", arg0, arg1)
+	ret0, _ := ret[0].(model.Redirection)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Unlock indicates an expected call of Unlock.
+func (mr *MockShortenerServiceMockRecorder) Unlock(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unlock", reflect.TypeOf((*MockShortenerService)(nil).Unlock), arg0, arg1)
+}
+
+// Update mocks base method.
+func (m *MockShortenerService) Update(arg0, arg1, arg2 string, arg3 model.LinkUpdate) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Update indicates an expected call of Update.
+func (mr *MockShortenerServiceMockRecorder) Update(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockShortenerService)(nil).Update), arg0, arg1, arg2, arg3)
+}
+
+// MockBotClassifier is a mock of BotClassifier interface.
+type MockBotClassifier struct {
+	ctrl     *gomock.Controller
+	recorder *MockBotClassifierMockRecorder
+}
+
+// MockBotClassifierMockRecorder is the mock recorder for MockBotClassifier.
+type MockBotClassifierMockRecorder struct {
+	mock *MockBotClassifier
+}
+
+// NewMockBotClassifier creates a new mock instance.
+func NewMockBotClassifier(ctrl *gomock.Controller) *MockBotClassifier {
+	mock := &MockBotClassifier{ctrl: ctrl}
+	mock.recorder = &MockBotClassifierMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockBotClassifier) EXPECT() *MockBotClassifierMockRecorder {
+	return m.recorder
+}
+
+// IsBot mocks base method.
+func (m *MockBotClassifier) IsBot(arg0 string) bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsBot", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsBot indicates an expected call of IsBot.
+func (mr *MockBotClassifierMockRecorder) IsBot(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsBot", reflect.TypeOf((*MockBotClassifier)(nil).IsBot), arg0)
+}
+
+// MockCountryResolver is a mock of CountryResolver interface.
+type MockCountryResolver struct {
+	ctrl     *gomock.Controller
+	recorder *MockCountryResolverMockRecorder
+}
+
+// MockCountryResolverMockRecorder is the mock recorder for MockCountryResolver.
+type MockCountryResolverMockRecorder struct {
+	mock *MockCountryResolver
+}
+
+// NewMockCountryResolver creates a new mock instance.
+func NewMockCountryResolver(ctrl *gomock.Controller) *MockCountryResolver {
+	mock := &MockCountryResolver{ctrl: ctrl}
+	mock.recorder = &MockCountryResolverMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCountryResolver) EXPECT() *MockCountryResolverMockRecorder {
+	return m.recorder
+}
+
+// Country mocks base method.
+func (m *MockCountryResolver) Country(arg0 net.IP) string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Country", arg0)
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Country indicates an expected call of Country.
+func (mr *MockCountryResolverMockRecorder) Country(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Shorten", reflect.TypeOf((*MockShortenerService)(nil).Shorten), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Country", reflect.TypeOf((*MockCountryResolver)(nil).Country), arg0)
 }
@@ -1,34 +1,124 @@
 package main
 
 import (
+	crand "crypto/rand"
 	"dh-url-shortener/config"
 	"dh-url-shortener/internal/api/handler"
 	"dh-url-shortener/internal/api/service"
+	"dh-url-shortener/internal/platform/botfilter"
 	"dh-url-shortener/internal/platform/db"
+	"dh-url-shortener/internal/platform/geoip"
+	"dh-url-shortener/internal/platform/policy"
 	dbSnapshot "dh-url-shortener/internal/platform/snapshot"
+	"dh-url-shortener/internal/platform/wordfilter"
 	"fmt"
 	"log"
+	"math/rand"
+	"net/url"
 	"os"
+	"strings"
+	"time"
 )
 
 func main() {
-	c := config.NewConfig(log.New(os.Stdout, "", log.LstdFlags))
+	c, err := config.NewConfig(log.New(os.Stdout, "", log.LstdFlags))
+	if err != nil {
+		log.Fatal(err)
+	}
+	rand.Seed(time.Now().UnixNano())
 	fmt.Printf("Config: %#v\n", c)
 	s := NewHTTPServer(c)
 	inMemoryDB := db.NewInMemoryDB()
 	snapshot := dbSnapshot.NewSnapshot(c.DBSnapshotPath, c.SnapshotSaveInterval)
-	err := snapshot.Restore(inMemoryDB)
+	err = snapshot.Restore(inMemoryDB)
 	if err != nil {
 		log.Fatal(err)
 	}
 	go snapshot.SavePeriodically(inMemoryDB, nil)
 
-	shortenerService := service.Shortener{DB: inMemoryDB, ShortURLDomain: c.ShortURLDomain}
-	h := handler.URLHandler{ShortenerService: shortenerService}
+	shortenerService := service.Shortener{
+		DB:                  inMemoryDB,
+		ShortURLDomain:      c.ShortURLDomain,
+		StripTrackingParams: c.StripTrackingParams,
+		SafeHashes:          c.SafeHashes,
+		PasswordAttempts:    service.NewAttemptLimiter(c.PasswordMaxAttempts, c.PasswordAttemptsTTL),
+	}
+	if c.PolicyPath != "" {
+		domainPolicy := policy.NewPolicy(c.PolicyPath)
+		domainPolicy.Logger = c.Logger
+		if err = domainPolicy.Load(); err != nil {
+			log.Fatal(err)
+		}
+		go domainPolicy.ReloadPeriodically(c.PolicyReloadInterval, nil)
+		shortenerService.Policy = domainPolicy
+	}
+	visitorSecret := []byte(c.VisitorSecret)
+	if len(visitorSecret) == 0 {
+		visitorSecret = make([]byte, 32)
+		if _, err = crand.Read(visitorSecret); err != nil {
+			log.Fatal(err)
+		}
+	}
+	shortenerService.Visitors = service.NewFingerprinter(visitorSecret)
+	shortenerService.TopLinks = service.NewTopLinks(c.TopLinksCapacity)
+	shortenerService.TopLinks.Restore(inMemoryDB.Data())
+	shortenerService.Events = service.NewEventHub(c.EventBufferSize)
+	if c.WordFilterPath != "" {
+		words, wordsErr := wordfilter.Open(c.WordFilterPath)
+		if wordsErr != nil {
+			log.Fatal(wordsErr)
+		}
+		shortenerService.Words = words
+	}
+	domainRedirects := make(map[string]handler.DomainRedirects)
+	for _, domain := range c.ShortURLDomains {
+		if domain.URL != c.ShortURLDomain {
+			shortenerService.ShortURLDomains = append(shortenerService.ShortURLDomains, domain.URL)
+		}
+		if u, parseErr := url.Parse(domain.URL); parseErr == nil {
+			domainRedirects[strings.ToLower(u.Host)] = handler.DomainRedirects{RootURL: domain.RootURL, NotFoundURL: domain.NotFoundURL}
+		}
+	}
+	h := handler.URLHandler{
+		ShortenerService:    shortenerService,
+		NotYetActiveURL:     c.NotYetActiveURL,
+		MaxBulkSize:         c.MaxBulkSize,
+		DomainRedirects:     domainRedirects,
+		TrustedProxies:      c.TrustedProxies,
+		DefaultRedirectMode: c.DefaultRedirectMode,
+	}
+	h.Bots = botfilter.Default()
+	if c.BotSignaturesPath != "" {
+		bots, botsErr := botfilter.Open(c.BotSignaturesPath)
+		if botsErr != nil {
+			log.Fatal(botsErr)
+		}
+		h.Bots = bots
+	}
+	if c.GeoIPPath != "" {
+		countries, geoErr := geoip.Open(c.GeoIPPath)
+		if geoErr != nil {
+			log.Fatal(geoErr)
+		}
+		h.Countries = countries
+	}
+	s.NotFoundHandler = h.NotFound
 
+	s.Get("/", h.Root, s.AccessLogMiddleware)
 	s.Post("/shorten", h.Shorten, s.AccessLogMiddleware)
+	s.Post("/shorten/bulk", h.BulkShorten, s.AccessLogMiddleware)
 	s.Get("/:hash", h.Expand, s.AccessLogMiddleware)
+	s.Get("/:hash/*", h.Expand, s.AccessLogMiddleware)
+	s.Get("/:hash+", h.Preview, s.AccessLogMiddleware)
+	s.Post("/:hash", h.Unlock, s.AccessLogMiddleware)
+	s.Post("/:hash/*", h.Unlock, s.AccessLogMiddleware)
 	s.Get("/list", h.List, s.AccessLogMiddleware)
+	s.Put("/links/:hash", h.Update, s.AccessLogMiddleware)
+	s.Get("/links/:hash/history", h.History, s.AccessLogMiddleware)
+	s.Post("/links/:hash/restore", h.RestoreRevision, s.AccessLogMiddleware)
+	s.Get("/links/:hash/stats", h.Stats, s.AccessLogMiddleware)
+	s.Get("/links/top", h.Top, s.AccessLogMiddleware)
+	s.Get("/events", h.Events, s.AccessLogMiddleware)
 
 	log.Fatal(s.ListenAndServe())
 }
@@ -5,24 +5,29 @@ import (
 	"fmt"
 	"net/http"
 	"regexp"
+	"strings"
 )
 
-var expandRe = regexp.MustCompile(`^/[a-z0-9A-Z]{7}$`)
+// hashRe matches the :hash segments of the routes, the hashes are seven characters long and the safe hashes are eight
+var hashRe = regexp.MustCompile(`^[a-z0-9A-Z]{7,8}$`)
 
 // HTTPServer is the server that handles the HTTP requests
 type HTTPServer struct {
 	ServerMux  *http.ServeMux
 	routeTable map[string]http.HandlerFunc
 	Config     *config.Config
+	// NotFoundHandler handles the requests which do not match any route
+	NotFoundHandler http.HandlerFunc
 }
 
 // NewHTTPServer creates a new HTTPServer
 func NewHTTPServer(c *config.Config) *HTTPServer {
 	mux := http.NewServeMux()
 	server := &HTTPServer{
-		ServerMux:  mux,
-		routeTable: make(map[string]http.HandlerFunc),
-		Config:     c,
+		ServerMux:       mux,
+		routeTable:      make(map[string]http.HandlerFunc),
+		Config:          c,
+		NotFoundHandler: http.NotFound,
 	}
 
 	return server
@@ -36,6 +41,14 @@ func (s *HTTPServer) Get(path string, handler http.HandlerFunc, middlewares ...f
 	s.routeTable[http.MethodGet+" "+path] = handler
 }
 
+// Head is a shortcut for mapping HEAD requests to the specified path.
+func (s *HTTPServer) Head(path string, handler http.HandlerFunc, middlewares ...func(http.HandlerFunc) http.HandlerFunc) {
+	for _, m := range middlewares {
+		handler = m(handler)
+	}
+	s.routeTable[http.MethodHead+" "+path] = handler
+}
+
 // Post is a shortcut for mapping POST requests to the specified path.
 func (s *HTTPServer) Post(path string, handler http.HandlerFunc, middlewares ...func(http.HandlerFunc) http.HandlerFunc) {
 	for _, m := range middlewares {
@@ -44,19 +57,74 @@ func (s *HTTPServer) Post(path string, handler http.HandlerFunc, middlewares ...
 	s.routeTable[http.MethodPost+" "+path] = handler
 }
 
-// ServeHTTP routes the request to the appropriate handler
+// Put is a shortcut for mapping PUT requests to the specified path.
+func (s *HTTPServer) Put(path string, handler http.HandlerFunc, middlewares ...func(http.HandlerFunc) http.HandlerFunc) {
+	for _, m := range middlewares {
+		handler = m(handler)
+	}
+	s.routeTable[http.MethodPut+" "+path] = handler
+}
+
+// ServeHTTP routes the request to the appropriate handler. HEAD requests are routed to the GET handler of the path
+// when the path has no HEAD handler, the response body is discarded by the HTTP server.
 func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	handler, ok := s.routeTable[r.Method+" "+r.URL.Path]
+	handler, ok := s.routeHandler(r.Method, r.URL.Path)
+	if !ok && r.Method == http.MethodHead {
+		handler, ok = s.routeHandler(http.MethodGet, r.URL.Path)
+	}
 	if !ok {
-		if expandRe.MatchString(r.URL.Path) {
-			handler = s.routeTable["GET /:hash"]
-		} else {
-			handler = http.NotFound
-		}
+		handler = s.NotFoundHandler
 	}
 	handler(w, r)
 }
 
+// routeHandler finds the handler of the route which matches the method and the path, either exactly or with its :hash segments.
+func (s *HTTPServer) routeHandler(method, path string) (http.HandlerFunc, bool) {
+	if handler, ok := s.routeTable[method+" "+path]; ok {
+		return handler, true
+	}
+	return s.dynamicRouteHandler(method, path)
+}
+
+// dynamicRouteHandler finds the handler of the route which matches the path with its :hash segments.
+func (s *HTTPServer) dynamicRouteHandler(method, path string) (http.HandlerFunc, bool) {
+	pathSegments := strings.Split(path, "/")
+	for route, handler := range s.routeTable {
+		methodAndPath := strings.SplitN(route, " ", 2)
+		if methodAndPath[0] == method && matchSegments(strings.Split(methodAndPath[1], "/"), pathSegments) {
+			return handler, true
+		}
+	}
+	return nil, false
+}
+
+// matchSegments reports whether the path segments match the route segments, :hash route segments match any valid hash
+// followed by the rest of the route segment, e.g. :hash+ matches abc1234+, and a trailing * route segment matches one
+// or more path segments.
+func matchSegments(routeSegments, pathSegments []string) bool {
+	last := len(routeSegments) - 1
+	if routeSegments[last] == "*" {
+		if len(pathSegments) <= last || pathSegments[last] == "" {
+			return false
+		}
+		routeSegments, pathSegments = routeSegments[:last], pathSegments[:last]
+	}
+	if len(routeSegments) != len(pathSegments) {
+		return false
+	}
+	for i, segment := range routeSegments {
+		if strings.HasPrefix(segment, ":hash") {
+			suffix := strings.TrimPrefix(segment, ":hash")
+			if !strings.HasSuffix(pathSegments[i], suffix) || !hashRe.MatchString(strings.TrimSuffix(pathSegments[i], suffix)) {
+				return false
+			}
+		} else if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
 // ListenAndServe starts the HTTP server
 func (s *HTTPServer) ListenAndServe() error {
 	s.ServerMux.Handle("/", s)
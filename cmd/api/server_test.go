@@ -16,7 +16,7 @@ import (
 func TestHttpServer_AccessLogMiddleware(t *testing.T) {
 	buf := new(bytes.Buffer)
 	logger := log.New(buf, "", log.LstdFlags)
-	c := config.NewConfig(logger)
+	c, _ := config.NewConfig(logger)
 	s := NewHTTPServer(c)
 
 	nextHandler := func(w http.ResponseWriter, r *http.Request) {
@@ -36,7 +36,7 @@ func TestHttpServer_AccessLogMiddleware(t *testing.T) {
 func TestHTTPServer_Get(t *testing.T) {
 	buf := new(bytes.Buffer)
 	logger := log.New(buf, "", log.LstdFlags)
-	c := config.NewConfig(logger)
+	c, _ := config.NewConfig(logger)
 	s := NewHTTPServer(c)
 
 	handler := func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "Hello World") }
@@ -49,7 +49,7 @@ func TestHTTPServer_Get(t *testing.T) {
 func TestHTTPServer_Post(t *testing.T) {
 	buf := new(bytes.Buffer)
 	logger := log.New(buf, "", log.LstdFlags)
-	c := config.NewConfig(logger)
+	c, _ := config.NewConfig(logger)
 	s := NewHTTPServer(c)
 
 	handler := func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "Hello World") }
@@ -62,7 +62,7 @@ func TestHTTPServer_Post(t *testing.T) {
 func TestHTTPServer_ServeHTTP_ShouldReturnStatusNotFoundWhenHandlerNotFoundForGivenEndpoint(t *testing.T) {
 	buf := new(bytes.Buffer)
 	logger := log.New(buf, "", log.LstdFlags)
-	c := config.NewConfig(logger)
+	c, _ := config.NewConfig(logger)
 	s := NewHTTPServer(c)
 
 	r, _ := http.NewRequest("GET", "http://localhost:8080/not-existing-endpoint", http.NoBody)
@@ -74,7 +74,7 @@ func TestHTTPServer_ServeHTTP_ShouldReturnStatusNotFoundWhenHandlerNotFoundForGi
 func TestHTTPServer_ServeHTTP_ShouldHandleDynamicHashVariable(t *testing.T) {
 	buf := new(bytes.Buffer)
 	logger := log.New(buf, "", log.LstdFlags)
-	c := config.NewConfig(logger)
+	c, _ := config.NewConfig(logger)
 	s := NewHTTPServer(c)
 	s.Get("/:hash", func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "Hello World") })
 	r, _ := http.NewRequest("GET", "http://localhost:8080/sevenCh", http.NoBody)
@@ -83,3 +83,119 @@ func TestHTTPServer_ServeHTTP_ShouldHandleDynamicHashVariable(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, "Hello World", w.Body.String())
 }
+
+func TestHTTPServer_ServeHTTP_ShouldRouteHeadRequestsToGetHandlers(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.New(buf, "", log.LstdFlags)
+	c, _ := config.NewConfig(logger)
+	s := NewHTTPServer(c)
+	redirect := func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://www.yemeksepeti.com/istanbul", http.StatusFound)
+	}
+	s.Get("/:hash", redirect)
+	s.Get("/:hash/*", redirect)
+	s.Get("/list", func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "[]") })
+
+	for _, path := range []string{"/05bf184", "/05bf184/menu"} {
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, httptest.NewRequest(http.MethodHead, path, nil))
+		assert.Equal(t, http.StatusFound, w.Code, path)
+		assert.Equal(t, "https://www.yemeksepeti.com/istanbul", w.Header().Get("Location"), path)
+	}
+
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/list", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/links/top", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHTTPServer_ServeHTTP_ShouldHandleDynamicHashVariableForPostRequests(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.New(buf, "", log.LstdFlags)
+	c, _ := config.NewConfig(logger)
+	s := NewHTTPServer(c)
+	s.Get("/:hash", func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "GET") })
+	s.Post("/:hash", func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "POST") })
+	r, _ := http.NewRequest("POST", "http://localhost:8080/sevenCh", http.NoBody)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "POST", w.Body.String())
+}
+
+func TestHTTPServer_Head(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.New(buf, "", log.LstdFlags)
+	c, _ := config.NewConfig(logger)
+	s := NewHTTPServer(c)
+
+	s.Head("/:hash", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusFound) }, s.AccessLogMiddleware)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, httptest.NewRequest(http.MethodHead, "/05bf184", nil))
+
+	assert.Equal(t, http.StatusFound, w.Code)
+}
+
+func TestHTTPServer_Put(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.New(buf, "", log.LstdFlags)
+	c, _ := config.NewConfig(logger)
+	s := NewHTTPServer(c)
+
+	handler := func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "Hello World") }
+	s.Put("/test", handler, s.AccessLogMiddleware)
+	_, ok := s.routeTable["PUT /test"]
+
+	assert.True(t, ok)
+}
+
+func TestHTTPServer_ServeHTTP_ShouldHandleHashVariableInNestedPaths(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.New(buf, "", log.LstdFlags)
+	c, _ := config.NewConfig(logger)
+	s := NewHTTPServer(c)
+	s.Get("/:hash", func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "expand") })
+	s.Get("/links/:hash/history", func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "history") })
+
+	s.Get("/:hash/*", func(w http.ResponseWriter, r *http.Request) { _, _ = io.WriteString(w, "expand") })
+	s.Get("/:hash+", func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusAccepted) })
+
+	tests := map[string]int{
+		"/sevenCh+":                   http.StatusAccepted,
+		"/sevenCh++":                  http.StatusNotFound,
+		"/sixChr+":                    http.StatusNotFound,
+		"/sevenCh/extra":              http.StatusOK,
+		"/sevenCh/extra/path":         http.StatusOK,
+		"/sevenCh/":                   http.StatusNotFound,
+		"/sixChr/extra":               http.StatusNotFound,
+		"/links/sevenCh/history":      http.StatusOK,
+		"/links/sixChr/history":       http.StatusNotFound,
+		"/links/sevenCh/history/more": http.StatusNotFound,
+		"/links/sevenCh":              http.StatusNotFound,
+	}
+	for path, expectedStatus := range tests {
+		r, _ := http.NewRequest("GET", "http://localhost:8080"+path, http.NoBody)
+		w := httptest.NewRecorder()
+		s.ServeHTTP(w, r)
+		assert.Equal(t, expectedStatus, w.Code, path)
+	}
+}
+
+func TestHTTPServer_ServeHTTP_ShouldUseNotFoundHandlerWhenHandlerNotFoundForGivenEndpoint(t *testing.T) {
+	buf := new(bytes.Buffer)
+	logger := log.New(buf, "", log.LstdFlags)
+	c, _ := config.NewConfig(logger)
+	s := NewHTTPServer(c)
+	s.NotFoundHandler = func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "https://tujix.me/404", http.StatusFound)
+	}
+
+	r, _ := http.NewRequest("GET", "http://localhost:8080/not-existing-endpoint", http.NoBody)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusFound, w.Code)
+	assert.Equal(t, "https://tujix.me/404", w.Header().Get("Location"))
+}
@@ -1,8 +1,13 @@
 package config
 
 import (
+	"dh-url-shortener/internal/api/model"
+	"fmt"
 	"log"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -10,16 +15,58 @@ type Config struct {
 	Addr                 string
 	DBSnapshotPath       string
 	ShortURLDomain       string
+	ShortURLDomains      []Domain
+	StripTrackingParams  bool
+	SafeHashes           bool
+	WordFilterPath       string
+	BotSignaturesPath    string
+	VisitorSecret        Secret
+	TopLinksCapacity     int
+	EventBufferSize      int
+	PolicyPath           string
+	PolicyReloadInterval time.Duration
+	NotYetActiveURL      string
+	PasswordMaxAttempts  int
+	PasswordAttemptsTTL  time.Duration
+	MaxBulkSize          int
+	GeoIPPath            string
+	DefaultRedirectMode  string
+	TrustedProxies       []*net.IPNet
 	Logger               *log.Logger
 	SnapshotSaveInterval time.Duration
 }
 
+// Secret is a config value which is masked when the config is printed.
+type Secret string
+
+// String masks the secret.
+func (s Secret) String() string {
+	return "***"
+}
+
+// GoString masks the secret.
+func (s Secret) GoString() string {
+	return `"***"`
+}
+
+// Domain is a short URL domain with its own redirect targets for its root path and its unknown links.
+type Domain struct {
+	URL         string
+	RootURL     string
+	NotFoundURL string
+}
+
 const defaultAddr = ":8080"
 const defaultShortURLDomain = "http://localhost:8080"
+const defaultMaxBulkSize = 1000
+const defaultRedirectMode = model.RedirectFound
 
-func NewConfig(logger *log.Logger) *Config {
+// NewConfig creates the config from the environment variables, an error is returned when one of them is invalid.
+func NewConfig(logger *log.Logger) (*Config, error) {
 	addr := os.Getenv("APP_ADDR")
 	shortURLDomain := os.Getenv("SHORT_URL_DOMAIN")
+	stripTrackingParams := os.Getenv("STRIP_TRACKING_PARAMS") == "true"
+	maxBulkSize, err := strconv.Atoi(os.Getenv("MAX_BULK_SIZE"))
 
 	if addr == "" {
 		addr = defaultAddr
@@ -29,11 +76,95 @@ func NewConfig(logger *log.Logger) *Config {
 		shortURLDomain = defaultShortURLDomain
 	}
 
+	if err != nil || maxBulkSize < 0 {
+		maxBulkSize = defaultMaxBulkSize
+	}
+
+	redirectMode, err := parseRedirectMode(os.Getenv("DEFAULT_REDIRECT_MODE"))
+	if err != nil {
+		return nil, err
+	}
+
 	return &Config{
 		Addr:                 addr,
 		ShortURLDomain:       shortURLDomain,
+		ShortURLDomains:      parseDomains(os.Getenv("SHORT_URL_DOMAINS")),
+		StripTrackingParams:  stripTrackingParams,
+		SafeHashes:           os.Getenv("SAFE_HASHES") == "true",
+		WordFilterPath:       os.Getenv("WORD_FILTER_PATH"),
+		BotSignaturesPath:    os.Getenv("BOT_SIGNATURES_PATH"),
+		VisitorSecret:        Secret(os.Getenv("VISITOR_SECRET")),
+		TopLinksCapacity:     1000,
+		EventBufferSize:      64,
+		PolicyPath:           os.Getenv("POLICY_PATH"),
+		PolicyReloadInterval: 10 * time.Second,
+		NotYetActiveURL:      os.Getenv("NOT_YET_ACTIVE_URL"),
+		PasswordMaxAttempts:  5,
+		PasswordAttemptsTTL:  15 * time.Minute,
+		MaxBulkSize:          maxBulkSize,
+		GeoIPPath:            os.Getenv("GEOIP_PATH"),
+		DefaultRedirectMode:  redirectMode,
+		TrustedProxies:       parseNetworks(os.Getenv("TRUSTED_PROXIES")),
 		Logger:               logger,
 		DBSnapshotPath:       "snapshot.db",
 		SnapshotSaveInterval: 5 * time.Second,
+	}, nil
+}
+
+// parseRedirectMode returns the given redirect mode, or the default mode when it is empty.
+// An unknown mode is an error so that a typo does not silently fall back to another mode.
+func parseRedirectMode(value string) (string, error) {
+	if value == "" {
+		return defaultRedirectMode, nil
+	}
+	if !model.IsRedirectMode(value) {
+		return "", fmt.Errorf("unknown DEFAULT_REDIRECT_MODE %q", value)
+	}
+	return value, nil
+}
+
+// parseDomains parses the comma separated domains. Each domain is in the form of url|root_url|not_found_url
+// where the root and not found redirect targets are optional, e.g. https://a.co|https://a.co/home,https://b.co
+func parseDomains(value string) []Domain {
+	var domains []Domain
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), "|")
+		if parts[0] == "" {
+			continue
+		}
+
+		domain := Domain{URL: strings.TrimSuffix(parts[0], "/")}
+		if len(parts) > 1 {
+			domain.RootURL = parts[1]
+		}
+		if len(parts) > 2 {
+			domain.NotFoundURL = parts[2]
+		}
+		domains = append(domains, domain)
+	}
+	return domains
+}
+
+// parseNetworks parses the comma separated networks. Each network is either a CIDR or a single IP,
+// e.g. 10.0.0.0/8,192.168.1.10. The invalid networks are ignored.
+func parseNetworks(value string) []*net.IPNet {
+	var networks []*net.IPNet
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				continue
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			entry = ip.String() + "/" + strconv.Itoa(bits)
+		}
+		if _, network, err := net.ParseCIDR(entry); err == nil {
+			networks = append(networks, network)
+		}
 	}
+	return networks
 }
@@ -1,30 +1,169 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func newTestConfig(t *testing.T) *Config {
+	c, err := NewConfig(nil)
+	assert.Nil(t, err)
+	return c
+}
+
 func TestNewConfig_ShouldUseDefaultAddrWhenEnvVariableIsNotSet(t *testing.T) {
-	c := NewConfig(nil)
+	c := newTestConfig(t)
 	assert.Equal(t, defaultAddr, c.Addr)
 }
 
 func TestNewConfig_ShouldUseAddrWhenEnvVariableIsSet(t *testing.T) {
 	_ = os.Setenv("APP_ADDR", ":30")
-	c := NewConfig(nil)
+	c := newTestConfig(t)
 	assert.Equal(t, ":30", c.Addr)
 }
 
 func TestNewConfig_ShouldUseDefaultShortURLDomainWhenEnvVariableIsNotSet(t *testing.T) {
-	c := NewConfig(nil)
+	c := newTestConfig(t)
 	assert.Equal(t, defaultShortURLDomain, c.ShortURLDomain)
 }
 
 func TestNewConfig_ShouldUseShortURLDomainFromEnvVariableIfItIsSetted(t *testing.T) {
 	_ = os.Setenv("SHORT_URL_DOMAIN", "tujix.me")
-	c := NewConfig(nil)
+	c := newTestConfig(t)
 	assert.Equal(t, "tujix.me", c.ShortURLDomain)
 }
+
+func TestNewConfig_ShouldNotStripTrackingParamsWhenEnvVariableIsNotSet(t *testing.T) {
+	c := newTestConfig(t)
+	assert.False(t, c.StripTrackingParams)
+}
+
+func TestNewConfig_ShouldStripTrackingParamsWhenEnvVariableIsTrue(t *testing.T) {
+	_ = os.Setenv("STRIP_TRACKING_PARAMS", "true")
+	defer os.Unsetenv("STRIP_TRACKING_PARAMS")
+	c := newTestConfig(t)
+	assert.True(t, c.StripTrackingParams)
+}
+
+func TestNewConfig_ShouldUsePolicyPathFromEnvVariable(t *testing.T) {
+	_ = os.Setenv("POLICY_PATH", "policy.txt")
+	defer os.Unsetenv("POLICY_PATH")
+	c := newTestConfig(t)
+	assert.Equal(t, "policy.txt", c.PolicyPath)
+}
+
+func TestNewConfig_ShouldUseWordFilterPathFromEnvVariable(t *testing.T) {
+	_ = os.Setenv("WORD_FILTER_PATH", "words.txt")
+	defer os.Unsetenv("WORD_FILTER_PATH")
+	c := newTestConfig(t)
+	assert.Equal(t, "words.txt", c.WordFilterPath)
+}
+
+func TestNewConfig_ShouldUseBotSignaturesPathFromEnvVariable(t *testing.T) {
+	_ = os.Setenv("BOT_SIGNATURES_PATH", "bots.txt")
+	defer os.Unsetenv("BOT_SIGNATURES_PATH")
+	c := newTestConfig(t)
+	assert.Equal(t, "bots.txt", c.BotSignaturesPath)
+}
+
+func TestNewConfig_ShouldUseNotYetActiveURLFromEnvVariable(t *testing.T) {
+	_ = os.Setenv("NOT_YET_ACTIVE_URL", "https://tujix.me/soon")
+	defer os.Unsetenv("NOT_YET_ACTIVE_URL")
+	c := newTestConfig(t)
+	assert.Equal(t, "https://tujix.me/soon", c.NotYetActiveURL)
+}
+
+func TestNewConfig_ShouldUseDefaultMaxBulkSizeWhenEnvVariableIsNotValid(t *testing.T) {
+	_ = os.Setenv("MAX_BULK_SIZE", "many")
+	defer os.Unsetenv("MAX_BULK_SIZE")
+	c := newTestConfig(t)
+	assert.Equal(t, defaultMaxBulkSize, c.MaxBulkSize)
+}
+
+func TestNewConfig_ShouldUseMaxBulkSizeFromEnvVariable(t *testing.T) {
+	_ = os.Setenv("MAX_BULK_SIZE", "50")
+	defer os.Unsetenv("MAX_BULK_SIZE")
+	c := newTestConfig(t)
+	assert.Equal(t, 50, c.MaxBulkSize)
+}
+
+func TestNewConfig_ShouldParseShortURLDomainsFromEnvVariable(t *testing.T) {
+	_ = os.Setenv("SHORT_URL_DOMAINS", "https://a.co|https://a.co/home|https://a.co/404, https://b.co/,,https://c.co||https://c.co/404")
+	defer os.Unsetenv("SHORT_URL_DOMAINS")
+	c := newTestConfig(t)
+	expected := []Domain{
+		{URL: "https://a.co", RootURL: "https://a.co/home", NotFoundURL: "https://a.co/404"},
+		{URL: "https://b.co"},
+		{URL: "https://c.co", NotFoundURL: "https://c.co/404"},
+	}
+	assert.Equal(t, expected, c.ShortURLDomains)
+}
+
+func TestNewConfig_ShouldNotHaveShortURLDomainsWhenEnvVariableIsNotSet(t *testing.T) {
+	c := newTestConfig(t)
+	assert.Empty(t, c.ShortURLDomains)
+}
+
+func TestNewConfig_ShouldParseTrustedProxiesFromEnvVariable(t *testing.T) {
+	_ = os.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.168.1.10,::1,invalid,300.0.0.0/8")
+	defer os.Unsetenv("TRUSTED_PROXIES")
+	c := newTestConfig(t)
+	var networks []string
+	for _, network := range c.TrustedProxies {
+		networks = append(networks, network.String())
+	}
+	assert.Equal(t, []string{"10.0.0.0/8", "192.168.1.10/32", "::1/128"}, networks)
+}
+
+func TestNewConfig_ShouldUseDefaultRedirectModeWhenEnvVariableIsNotSet(t *testing.T) {
+	c := newTestConfig(t)
+	assert.Equal(t, defaultRedirectMode, c.DefaultRedirectMode)
+}
+
+func TestNewConfig_ShouldUseRedirectModeFromEnvVariable(t *testing.T) {
+	_ = os.Setenv("DEFAULT_REDIRECT_MODE", "308")
+	defer os.Unsetenv("DEFAULT_REDIRECT_MODE")
+	c := newTestConfig(t)
+	assert.Equal(t, "308", c.DefaultRedirectMode)
+}
+
+func TestNewConfig_ShouldReturnErrorWhenRedirectModeIsUnknown(t *testing.T) {
+	_ = os.Setenv("DEFAULT_REDIRECT_MODE", "parmanent")
+	defer os.Unsetenv("DEFAULT_REDIRECT_MODE")
+	c, err := NewConfig(nil)
+	assert.EqualError(t, err, `unknown DEFAULT_REDIRECT_MODE "parmanent"`)
+	assert.Nil(t, c)
+}
+
+func TestParseRedirectMode_ShouldAcceptRedirectModes(t *testing.T) {
+	for _, value := range []string{"301", "302", "303", "307", "308", "meta-refresh", "js"} {
+		mode, err := parseRedirectMode(value)
+		assert.Nil(t, err)
+		assert.Equal(t, value, mode)
+	}
+}
+
+func TestParseRedirectMode_ShouldReturnErrorWhenModeIsUnknown(t *testing.T) {
+	for _, value := range []string{"30", "301 ", "meta_refresh", "JS"} {
+		_, err := parseRedirectMode(value)
+		assert.EqualError(t, err, fmt.Sprintf("unknown DEFAULT_REDIRECT_MODE %q", value))
+	}
+}
+
+func TestNewConfig_ShouldUseSafeHashesWhenEnvVariableIsSet(t *testing.T) {
+	_ = os.Setenv("SAFE_HASHES", "true")
+	defer os.Unsetenv("SAFE_HASHES")
+	c := newTestConfig(t)
+	assert.True(t, c.SafeHashes)
+}
+
+func TestNewConfig_ShouldMaskVisitorSecret(t *testing.T) {
+	_ = os.Setenv("VISITOR_SECRET", "s3cret")
+	defer os.Unsetenv("VISITOR_SECRET")
+	c := newTestConfig(t)
+	assert.Equal(t, Secret("s3cret"), c.VisitorSecret)
+	assert.NotContains(t, fmt.Sprintf("%#v %v %s", c, *c, c.VisitorSecret), "s3cret")
+}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+const (
+	ndjsonContentType = "application/x-ndjson"
+	maxNDJSONLineSize = 1024 * 1024
+	// maxBulkBodySize limits the body of a bulk request, so a large body is rejected before it is read to the end.
+	maxBulkBodySize = 32 * 1024 * 1024
+)
+
+var errBulkBodyNotArray = errors.New("bulk body must be a JSON array")
+
+// BulkShorten handles requests which are aim to shorten many long URLs at once.
+// The body is either a JSON array of shorten requests or newline delimited shorten requests (NDJSON),
+// and the response contains a result for each item in the input order.
+func (h URLHandler) BulkShorten(w http.ResponseWriter, r *http.Request) {
+	ws, err := workspace(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var items []json.RawMessage
+	body := &countingReader{r: io.LimitReader(r.Body, maxBulkBodySize+1)}
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == ndjsonContentType {
+		items, err = h.readNDJSON(body)
+	} else {
+		items, err = h.readJSONArray(body)
+	}
+	if body.n > maxBulkBodySize {
+		http.Error(w, fmt.Sprintf("bulk body cannot be larger than %d bytes", maxBulkBodySize), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if h.MaxBulkSize > 0 && len(items) > h.MaxBulkSize {
+		http.Error(w, fmt.Sprintf("bulk size cannot be more than %d", h.MaxBulkSize), http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	results := make([]BulkShortenResult, len(items))
+	for i, item := range items {
+		results[i] = h.shortenItem(i, item, ws)
+	}
+
+	h.json(w, http.StatusOK, &results)
+}
+
+// shortenItem shortens a single item of the bulk request in the given workspace.
+func (h URLHandler) shortenItem(index int, item json.RawMessage, ws string) BulkShortenResult {
+	result := BulkShortenResult{Index: index}
+
+	var sr ShortenRequest
+	if err := json.Unmarshal(item, &sr); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if err := sr.validate(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	options := sr.options()
+	options.Workspace = ws
+	shortURL, err := h.ShortenerService.Shorten(sr.URL, options)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.URL = shortURL
+	return result
+}
+
+// readJSONArray reads the items of the JSON array in the body one by one, it stops reading when there are more items
+// than MaxBulkSize.
+func (h URLHandler) readJSONArray(body io.Reader) ([]json.RawMessage, error) {
+	decoder := json.NewDecoder(body)
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('[') {
+		return nil, errBulkBodyNotArray
+	}
+
+	var items []json.RawMessage
+	for decoder.More() {
+		var item json.RawMessage
+		if err = decoder.Decode(&item); err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+		if h.MaxBulkSize > 0 && len(items) > h.MaxBulkSize {
+			return items, nil
+		}
+	}
+	_, err = decoder.Token()
+	return items, err
+}
+
+// readNDJSON reads the non-empty lines of the body, it stops reading when there are more lines than MaxBulkSize.
+func (h URLHandler) readNDJSON(body io.Reader) ([]json.RawMessage, error) {
+	var items []json.RawMessage
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxNDJSONLineSize)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		items = append(items, append(json.RawMessage(nil), line...))
+		if h.MaxBulkSize > 0 && len(items) > h.MaxBulkSize {
+			break
+		}
+	}
+
+	return items, scanner.Err()
+}
+
+// countingReader counts the bytes which are read from the underlying reader.
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type BulkShortenResult struct {
+	Index int    `json:"index"`
+	URL   string `json:"url,omitempty"`
+	Error string `json:"error,omitempty"`
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/service"
+	"dh-url-shortener/internal/platform/db"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLHandler_BulkShorten_ShouldReturnPerItemResultsInInputOrder(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	gomock.InOrder(
+		mockShortenerService.EXPECT().Shorten("https://yemeksepeti.com/1", gomock.Any()).Return(shortURLDomain+"/aaaaaaa", nil).Times(1),
+		mockShortenerService.EXPECT().Shorten("https://yemeksepeti.com/3", gomock.Any()).Return("", errors.New("service error")).Times(1),
+		mockShortenerService.EXPECT().Shorten("https://yemeksepeti.com/4", gomock.Any()).Return(shortURLDomain+"/bbbbbbb", nil).Times(1),
+	)
+
+	handler := URLHandler{ShortenerService: mockShortenerService}
+	body := `[{"url": "https://yemeksepeti.com/1"}, {"url": "invalid url"}, {"url": "https://yemeksepeti.com/3"}, {"url": "https://yemeksepeti.com/4"}]`
+	resp := httptest.NewRecorder()
+	handler.BulkShorten(resp, httptest.NewRequest(http.MethodPost, "/shorten/bulk", strings.NewReader(body)))
+
+	var results []BulkShortenResult
+	_ = json.Unmarshal(resp.Body.Bytes(), &results)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, results, 4)
+	assert.Equal(t, BulkShortenResult{Index: 0, URL: shortURLDomain + "/aaaaaaa"}, results[0])
+	assert.NotEmpty(t, results[1].Error)
+	assert.Equal(t, BulkShortenResult{Index: 2, Error: "service error"}, results[2])
+	assert.Equal(t, BulkShortenResult{Index: 3, URL: shortURLDomain + "/bbbbbbb"}, results[3])
+}
+
+// TestURLHandler_BulkShorten_ShouldAcceptNDJSON tests integration of bulk short url creation process from NDJSON
+func TestURLHandler_BulkShorten_ShouldAcceptNDJSON(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	body := "{\"url\": \"" + longURL + "\"}\n\nnot json\n{\"url\": \"" + longURL + "\", \"max_hits\": -1}\n"
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/shorten/bulk", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-ndjson; charset=utf-8")
+	handler.BulkShorten(resp, req)
+
+	var results []BulkShortenResult
+	_ = json.Unmarshal(resp.Body.Bytes(), &results)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, results, 3)
+	assert.Equal(t, BulkShortenResult{Index: 0, URL: shortURLDomain + "/05bf184"}, results[0])
+	assert.NotEmpty(t, results[1].Error)
+	assert.Equal(t, BulkShortenResult{Index: 2, Error: errInvalidMaxHits}, results[2])
+}
+
+func TestURLHandler_BulkShorten_ShouldReturnBadRequestWhenBodyIsNotJSONArray(t *testing.T) {
+	handler := URLHandler{}
+	resp := httptest.NewRecorder()
+	handler.BulkShorten(resp, httptest.NewRequest(http.MethodPost, "/shorten/bulk", strings.NewReader(`{"url": "https://yemeksepeti.com"}`)))
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestURLHandler_BulkShorten_ShouldReturnRequestEntityTooLargeWhenBodyIsTooLarge(t *testing.T) {
+	handler := URLHandler{}
+	for _, contentType := range []string{"application/json", "application/x-ndjson"} {
+		body := `[{"url": "https://yemeksepeti.com/1"}` + strings.Repeat(" ", maxBulkBodySize) + `]`
+		if contentType == "application/x-ndjson" {
+			body = `{"url": "https://yemeksepeti.com/1"}` + strings.Repeat("\n", maxBulkBodySize)
+		}
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/shorten/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		handler.BulkShorten(resp, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code, contentType)
+	}
+}
+
+func TestURLHandler_BulkShorten_ShouldStopReadingWhenBulkSizeIsExceeded(t *testing.T) {
+	handler := URLHandler{MaxBulkSize: 2}
+	// the body is not valid JSON after the third item, so it would be a bad request if it were read past the limit
+	body := `[{"url": "https://yemeksepeti.com/1"}, {"url": "https://yemeksepeti.com/2"}, {"url": "https://yemeksepeti.com/3"}, not json`
+	resp := httptest.NewRecorder()
+	handler.BulkShorten(resp, httptest.NewRequest(http.MethodPost, "/shorten/bulk", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code)
+}
+
+func TestURLHandler_BulkShorten_ShouldReturnRequestEntityTooLargeWhenBulkSizeIsExceeded(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Shorten(gomock.Any(), gomock.Any()).Times(0)
+
+	handler := URLHandler{ShortenerService: mockShortenerService, MaxBulkSize: 2}
+	for _, contentType := range []string{"application/json", "application/x-ndjson"} {
+		body := `[{"url": "https://yemeksepeti.com/1"}, {"url": "https://yemeksepeti.com/2"}, {"url": "https://yemeksepeti.com/3"}]`
+		if contentType == "application/x-ndjson" {
+			body = "{\"url\": \"https://yemeksepeti.com/1\"}\n{\"url\": \"https://yemeksepeti.com/2\"}\n{\"url\": \"https://yemeksepeti.com/3\"}"
+		}
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/shorten/bulk", strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		handler.BulkShorten(resp, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, resp.Code, contentType)
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// clientIP returns the IP of the visitor of the request. The X-Forwarded-For header is only used when the request
+// comes from a trusted proxy, and it is read from right to left since each proxy appends the address it receives
+// the request from. The first address which is not a trusted proxy is the visitor.
+func (h URLHandler) clientIP(r *http.Request) net.IP {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !h.trustedProxy(ip) {
+		return ip
+	}
+
+	forwarded := strings.Split(strings.Join(r.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(forwarded) - 1; i >= 0; i-- {
+		forwardedIP := net.ParseIP(strings.TrimSpace(forwarded[i]))
+		if forwardedIP == nil {
+			break
+		}
+		ip = forwardedIP
+		if !h.trustedProxy(ip) {
+			break
+		}
+	}
+	return ip
+}
+
+// trustedProxy reports whether the given IP is in one of the trusted proxy networks.
+func (h URLHandler) trustedProxy(ip net.IP) bool {
+	for _, network := range h.TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// country returns the country of the visitor of the request, or empty if it is unknown.
+func (h URLHandler) country(r *http.Request) string {
+	if h.Countries == nil {
+		return ""
+	}
+	ip := h.clientIP(r)
+	if ip == nil {
+		return ""
+	}
+	return h.Countries.Country(ip)
+}
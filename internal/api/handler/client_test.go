@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLHandler_clientIP(t *testing.T) {
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	handler := URLHandler{TrustedProxies: []*net.IPNet{proxies}}
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		expected     string
+	}{
+		{name: "direct request", remoteAddr: "78.170.1.1:5000", expected: "78.170.1.1"},
+		{name: "forwarded for is ignored from untrusted remote", remoteAddr: "78.170.1.1:5000", forwardedFor: []string{"1.0.0.1"}, expected: "78.170.1.1"},
+		{name: "forwarded for from trusted proxy", remoteAddr: "10.0.0.1:5000", forwardedFor: []string{"1.0.0.1"}, expected: "1.0.0.1"},
+		{name: "spoofed entries before the visitor are ignored", remoteAddr: "10.0.0.1:5000", forwardedFor: []string{"85.214.1.1, 1.0.0.1, 10.0.0.2"}, expected: "1.0.0.1"},
+		{name: "multiple forwarded for headers", remoteAddr: "10.0.0.1:5000", forwardedFor: []string{"85.214.1.1", "1.0.0.1"}, expected: "1.0.0.1"},
+		{name: "only trusted proxies", remoteAddr: "10.0.0.1:5000", forwardedFor: []string{"10.0.0.3, 10.0.0.2"}, expected: "10.0.0.3"},
+		{name: "invalid forwarded for entry", remoteAddr: "10.0.0.1:5000", forwardedFor: []string{"1.0.0.1, unknown"}, expected: "10.0.0.1"},
+		{name: "trusted proxy without forwarded for", remoteAddr: "10.0.0.1:5000", expected: "10.0.0.1"},
+		{name: "ipv6 remote", remoteAddr: "[2a02:ff0::1]:5000", expected: "2a02:ff0::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/05bf184", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, value := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", value)
+			}
+			assert.Equal(t, tt.expected, handler.clientIP(r).String())
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const errStreamingNotSupported = "streaming is not supported"
+
+// eventKeepAliveInterval is the interval of the comments which keep the idle event streams open through the proxies.
+const eventKeepAliveInterval = 15 * time.Second
+
+// Events streams the hits of the links in the workspace as Server-Sent Events, limited to the hashes of the repeated
+// hash query parameter. The events which a slow client can not keep up with are dropped, and their number is reported
+// in the next event.
+func (h URLHandler) Events(w http.ResponseWriter, r *http.Request) {
+	ws, err := workspace(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, errStreamingNotSupported, http.StatusInternalServerError)
+		return
+	}
+
+	events, cancel := h.ShortenerService.Subscribe(model.EventFilter{Workspace: ws, Hashes: r.URL.Query()["hash"]})
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventKeepAliveInterval)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			if _, err = fmt.Fprintf(w, "event: hit\ndata: %s\n\n", data); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
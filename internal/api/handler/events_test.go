@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"bufio"
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/api/service"
+	"dh-url-shortener/internal/platform/db"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestURLHandler_Events tests integration of the event stream
+func TestURLHandler_Events_ShouldStreamHitsOfHashes(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain, Events: service.NewEventHub(10)}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{})
+	ankara, _ := svc.Shorten("https://www.yemeksepeti.com/ankara", model.LinkOptions{})
+	server := httptest.NewServer(http.HandlerFunc(handler.Events))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/events?hash=05bf184")
+	assert.Nil(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	for _, target := range []string{ankara[len(shortURLDomain):], "/05bf184"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.Header.Set("Referer", "https://www.google.com/")
+		handler.Expand(httptest.NewRecorder(), req)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	event, _ := reader.ReadString('\n')
+	data, _ := reader.ReadString('\n')
+	assert.Equal(t, "event: hit\n", event)
+	assert.Regexp(t, `^data: \{"hash":"05bf184","time":"[^"]+","referrer":"www.google.com"\}\n$`, data)
+}
+
+func TestURLHandler_Events_ShouldEndWhenSubscriptionIsClosed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	events := make(chan model.HitEvent, 1)
+	events <- model.HitEvent{Hash: "05bf184", Dropped: 3}
+	close(events)
+	canceled := false
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Subscribe(model.EventFilter{Workspace: "team-a", Hashes: []string{"05bf184", "8d505df"}}).
+		Return((<-chan model.HitEvent)(events), func() { canceled = true }).Times(1)
+	handler := URLHandler{ShortenerService: mockShortenerService}
+
+	req := httptest.NewRequest(http.MethodGet, "/events?hash=05bf184&hash=8d505df", nil)
+	req.Header.Set(workspaceHeader, "team-a")
+	resp := httptest.NewRecorder()
+	handler.Events(resp, req)
+
+	assert.True(t, canceled)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "no-cache", resp.Header().Get("Cache-Control"))
+	assert.Equal(t, "event: hit\ndata: {\"hash\":\"05bf184\",\"time\":\"0001-01-01T00:00:00Z\",\"dropped\":3}\n\n", resp.Body.String())
+}
+
+func TestURLHandler_Events_ShouldReturnBadRequestWhenWorkspaceIsInvalid(t *testing.T) {
+	handler := URLHandler{ShortenerService: service.Shortener{DB: db.NewInMemoryDB()}}
+
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set(workspaceHeader, "team a")
+	resp := httptest.NewRecorder()
+	handler.Events(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+)
+
+var passwordFormTemplate = template.Must(template.New("password").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Password required</title>
+</head>
+<body>
+<h1>This link is password protected</h1>
+{{if .Error}}<p>{{.Error}}</p>{{end}}
+<form method="post" action="{{.Action}}">
+<input type="password" name="password" autofocus required>
+<button type="submit">Continue</button>
+</form>
+</body>
+</html>
+`))
+
+var redirectPageTemplate = template.Must(template.New("redirect").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+{{if not .Script}}<meta http-equiv="refresh" content="0; url={{.URL}}">{{end}}
+<title>Redirecting</title>
+</head>
+<body>
+<p>Redirecting to <a href="{{.URL}}">{{.URL}}</a></p>
+{{if .Script}}<script>window.location.replace({{.URL}});</script>{{end}}
+</body>
+</html>
+`))
+
+// redirectPage is the data of the redirect page template, the page redirects with JavaScript when Script is set
+// and with a meta refresh tag otherwise.
+type redirectPage struct {
+	URL    string
+	Script bool
+}
+
+var previewTemplate = template.Must(template.New("preview").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Link preview</title>
+</head>
+<body>
+<h1>{{if .Title}}{{.Title}}{{else}}Link preview{{end}}</h1>
+<p>This link goes to <a href="{{.URL}}" rel="noopener noreferrer">{{.URL}}</a></p>
+<ul>
+{{if not .CreatedAt.IsZero}}<li>Created on {{.CreatedAt.Format "2006-01-02 15:04 MST"}}</li>{{end}}
+<li>Followed {{.Hits}} times</li>
+</ul>
+<p><a href="/{{.Hash}}">Follow the link</a></p>
+</body>
+</html>
+`))
+
+var interstitialTemplate = template.Must(template.New("interstitial").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>You are leaving</title>
+</head>
+<body>
+<h1>You are about to leave for another site</h1>
+<p>This link goes to {{.URL}}</p>
+<p>Continue only if you trust the site.</p>
+<p><a href="{{.ContinueURL}}" role="button">Continue</a></p>
+</body>
+</html>
+`))
+
+// interstitialPage is the data of the interstitial template.
+type interstitialPage struct {
+	URL         string
+	ContinueURL string
+}
+
+// passwordForm is the data of the password form template.
+type passwordForm struct {
+	// Action is the URL which the password is posted to.
+	Action string
+	Error  string
+}
+
+// html renders the given template with data and writes it to the response writer.
+func (h URLHandler) html(w http.ResponseWriter, statusCode int, t *template.Template, data interface{}) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_ = t.Execute(w, data)
+}
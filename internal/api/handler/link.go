@@ -0,0 +1,131 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	errInvalidRevision = "revision cannot be negative"
+	errEmptyUpdate     = "at least one of url, title, tags and notes must be given"
+)
+
+// Update handles requests which are aim to change the destination or the metadata of a short URL.
+func (h URLHandler) Update(w http.ResponseWriter, r *http.Request) {
+	ws, err := workspace(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var ur UpdateRequest
+	if err = json.NewDecoder(r.Body).Decode(&ur); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = ur.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err = h.ShortenerService.Update(ws, linkDomain(r), pathHash(r.URL.Path), ur.update()); err != nil {
+		http.Error(w, err.Error(), errorStatusCode(err, http.StatusNotFound))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// History returns the destination revisions of a short URL.
+func (h URLHandler) History(w http.ResponseWriter, r *http.Request) {
+	ws, err := workspace(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	history, err := h.ShortenerService.History(ws, linkDomain(r), pathHash(r.URL.Path))
+	if err != nil {
+		http.Error(w, err.Error(), errorStatusCode(err, http.StatusNotFound))
+		return
+	}
+
+	h.json(w, http.StatusOK, &history)
+}
+
+// RestoreRevision handles requests which are aim to change the destination of a short URL back to a previous revision.
+func (h URLHandler) RestoreRevision(w http.ResponseWriter, r *http.Request) {
+	ws, err := workspace(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var rr RestoreRevisionRequest
+	if err = json.NewDecoder(r.Body).Decode(&rr); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if rr.Revision < 0 {
+		http.Error(w, errInvalidRevision, http.StatusBadRequest)
+		return
+	}
+
+	if err = h.ShortenerService.RestoreRevision(ws, linkDomain(r), pathHash(r.URL.Path), rr.Revision, rr.Actor); err != nil {
+		http.Error(w, err.Error(), errorStatusCode(err, http.StatusNotFound))
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// linkDomain returns the short URL domain host of the managed link from the domain query parameter, or the request host.
+func linkDomain(r *http.Request) string {
+	if domain := r.URL.Query().Get("domain"); domain != "" {
+		return domain
+	}
+	return r.Host
+}
+
+// pathHash returns the hash from the /links/:hash paths.
+func pathHash(path string) string {
+	segments := strings.Split(path, "/")
+	if len(segments) < 3 {
+		return ""
+	}
+	return segments[2]
+}
+
+type UpdateRequest struct {
+	URL   string    `json:"url"`
+	Actor string    `json:"actor"`
+	Title *string   `json:"title"`
+	Tags  *[]string `json:"tags"`
+	Notes *string   `json:"notes"`
+}
+
+// validate validates the UpdateRequest changes something and its URL field is a valid URL when it is given
+func (r UpdateRequest) validate() error {
+	if r.URL == "" {
+		if r.Title == nil && r.Tags == nil && r.Notes == nil {
+			return errors.New(errEmptyUpdate)
+		}
+		return nil
+	}
+	return validateURL(r.URL)
+}
+
+// update converts the UpdateRequest to model.LinkUpdate
+func (r UpdateRequest) update() model.LinkUpdate {
+	return model.LinkUpdate{URL: r.URL, Actor: r.Actor, Title: r.Title, Tags: r.Tags, Notes: r.Notes}
+}
+
+type RestoreRevisionRequest struct {
+	Revision int    `json:"revision"`
+	Actor    string `json:"actor"`
+}
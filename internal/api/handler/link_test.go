@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"bytes"
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/api/service"
+	"dh-url-shortener/internal/platform/db"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const newLongURL = "https://www.yemeksepeti.com/ankara"
+
+func TestURLHandler_Update_ShouldReturnBadRequestWhenURLIsNotValid(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Times(0)
+
+	handler := URLHandler{ShortenerService: mockShortenerService}
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/links/05bf184", bytes.NewReader([]byte(`{"url": "invalid url"}`)))
+	handler.Update(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestURLHandler_Update_ShouldReturnNotFoundWhenServiceReturnsError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Update("", "example.com", "05bf184", model.LinkUpdate{URL: newLongURL, Actor: "tuncay"}).Return(errors.New("05bf184 key not exists")).Times(1)
+
+	handler := URLHandler{ShortenerService: mockShortenerService}
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/links/05bf184", bytes.NewReader([]byte(`{"url": "`+newLongURL+`", "actor": "tuncay"}`)))
+	handler.Update(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestURLHandler_Update_ShouldReturnBadRequestWhenNothingIsChanged(t *testing.T) {
+	handler := URLHandler{}
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/links/05bf184", bytes.NewReader([]byte(`{"actor": "tuncay"}`)))
+	handler.Update(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+// TestURLHandler_Update_ShouldChangeMetadata tests integration of metadata update and tag filtering
+func TestURLHandler_Update_ShouldChangeMetadata(t *testing.T) {
+	InMemoryDB := db.NewInMemoryDB()
+	svc := service.Shortener{DB: InMemoryDB, ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{Metadata: model.Metadata{Title: "Istanbul", Tags: []string{"Food"}, Notes: "city page"}})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/links/05bf184", bytes.NewReader([]byte(`{"title": "Istanbul restaurants", "tags": ["food", " Campaign "]}`)))
+	handler.Update(resp, req)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	resp = httptest.NewRecorder()
+	handler.List(resp, httptest.NewRequest(http.MethodGet, "/list?tag=campaign", nil))
+	var list []model.ListData
+	_ = json.Unmarshal(resp.Body.Bytes(), &list)
+
+	assert.Len(t, list, 1)
+	assert.Equal(t, longURL, list[0].OriginalURL)
+	assert.Equal(t, "Istanbul restaurants", list[0].Title)
+	assert.Equal(t, []string{"food", "campaign"}, list[0].Tags)
+	assert.Equal(t, "city page", list[0].Notes)
+
+	history, _ := svc.History("", "", "05bf184")
+	assert.Empty(t, history)
+}
+
+func TestURLHandler_RestoreRevision_ShouldReturnBadRequestWhenRevisionIsNegative(t *testing.T) {
+	handler := URLHandler{}
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/links/05bf184/restore", bytes.NewReader([]byte(`{"revision": -1}`)))
+	handler.RestoreRevision(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+// TestURLHandler_RevisionHistory tests integration of destination update, history and restore processes
+func TestURLHandler_RevisionHistory(t *testing.T) {
+	InMemoryDB := db.NewInMemoryDB()
+	svc := service.Shortener{DB: InMemoryDB, ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPut, "/links/05bf184", bytes.NewReader([]byte(`{"url": "`+newLongURL+`", "actor": "tuncay"}`)))
+	handler.Update(resp, req)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodPost, "/links/05bf184/restore", bytes.NewReader([]byte(`{"revision": 0, "actor": "kilicoglu"}`)))
+	handler.RestoreRevision(resp, req)
+	assert.Equal(t, http.StatusNoContent, resp.Code)
+
+	resp = httptest.NewRecorder()
+	handler.History(resp, httptest.NewRequest(http.MethodGet, "/links/05bf184/history", nil))
+	var history []model.Revision
+	_ = json.Unmarshal(resp.Body.Bytes(), &history)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Len(t, history, 2)
+	assert.Equal(t, newLongURL, history[0].NewURL)
+	assert.Equal(t, "tuncay", history[0].Actor)
+	assert.Equal(t, longURL, history[1].NewURL)
+	assert.Equal(t, "kilicoglu", history[1].Actor)
+
+	redirectionData, _ := InMemoryDB.Get("05bf184")
+	assert.Equal(t, longURL, redirectionData.OriginalURL)
+}
+
+func TestURLHandler_RestoreRevision_ShouldReturnBadRequestWhenRevisionNotExists(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().RestoreRevision("", "example.com", "05bf184", 5, "").Return(model.ErrRevisionNotFound).Times(1)
+
+	handler := URLHandler{ShortenerService: mockShortenerService}
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/links/05bf184/restore", bytes.NewReader([]byte(`{"revision": 5}`)))
+	handler.RestoreRevision(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func TestURLHandler_History_ShouldReturnNotFoundWhenServiceReturnsError(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().History("", "example.com", "05bf184").Return(nil, errors.New("05bf184 not found")).Times(1)
+
+	handler := URLHandler{ShortenerService: mockShortenerService}
+	resp := httptest.NewRecorder()
+	handler.History(resp, httptest.NewRequest(http.MethodGet, "/links/05bf184/history", nil))
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
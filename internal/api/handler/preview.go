@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const (
+	// previewParam shows the preview of the short URL instead of redirecting when it is 1, e.g. /abc1234?preview=1
+	previewParam = "preview"
+	// confirmParam confirms the destination of a link with an interstitial page when it is 1
+	confirmParam = "confirm"
+)
+
+// Preview shows where the short URL goes without redirecting or counting a hit, e.g. /abc1234+
+func (h URLHandler) Preview(w http.ResponseWriter, r *http.Request) {
+	hash, ok := h.checkHash(w, r, strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/"), "+"))
+	if !ok {
+		return
+	}
+	h.preview(w, r, hash)
+}
+
+// preview renders the preview page of the short URL with the given hash.
+func (h URLHandler) preview(w http.ResponseWriter, r *http.Request, hash string) {
+	preview, err := h.ShortenerService.Preview(r.Host, hash)
+	if errors.Is(err, model.ErrLinkNotFound) {
+		h.notFound(w, r, err.Error())
+		return
+	}
+	if errors.Is(err, model.ErrLinkNotActive) && h.NotYetActiveURL != "" {
+		http.Redirect(w, r, h.NotYetActiveURL, http.StatusFound)
+		return
+	}
+	if errors.Is(err, model.ErrPasswordRequired) {
+		h.html(w, http.StatusUnauthorized, passwordFormTemplate, passwordForm{Action: passwordAction(r)})
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), errorStatusCode(err, http.StatusNotFound))
+		return
+	}
+
+	h.html(w, http.StatusOK, previewTemplate, preview)
+}
+
+// interstitial renders the warning page which the visitor must confirm before being redirected to the destination.
+// The continue button requests the same URL with the confirm parameter.
+func (h URLHandler) interstitial(w http.ResponseWriter, r *http.Request, destination string) {
+	continueURL := *r.URL
+	query := continueURL.Query()
+	query.Set(confirmParam, "1")
+	continueURL.RawQuery = query.Encode()
+
+	w.Header().Set("Cache-Control", "no-store")
+	h.html(w, http.StatusOK, interstitialTemplate, interstitialPage{URL: destination, ContinueURL: continueURL.RequestURI()})
+}
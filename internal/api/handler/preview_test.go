@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/api/service"
+	"dh-url-shortener/internal/platform/db"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestURLHandler_Preview_ShouldNotRedirectOrCountHit tests integration of the preview
+func TestURLHandler_Preview_ShouldNotRedirectOrCountHit(t *testing.T) {
+	createdAt := time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC)
+	InMemoryDB := db.NewInMemoryDB()
+	svc := service.Shortener{DB: InMemoryDB, ShortURLDomain: shortURLDomain, Clock: func() time.Time { return createdAt }}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{Metadata: model.Metadata{Title: "Istanbul <restaurants>"}})
+
+	for _, path := range []string{"/05bf184+", "/05bf184?preview=1"} {
+		resp := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		if path == "/05bf184+" {
+			handler.Preview(resp, r)
+		} else {
+			handler.Expand(resp, r)
+		}
+
+		assert.Equal(t, http.StatusOK, resp.Code, path)
+		assert.Empty(t, resp.Header().Get("Location"), path)
+		assert.Contains(t, resp.Body.String(), `<a href="https://www.yemeksepeti.com/istanbul"`, path)
+		assert.Contains(t, resp.Body.String(), "Istanbul &lt;restaurants&gt;", path)
+		assert.Contains(t, resp.Body.String(), "Created on 2022-06-01 09:00 UTC", path)
+		assert.Contains(t, resp.Body.String(), "Followed 0 times", path)
+	}
+
+	redirectionData, _ := InMemoryDB.Get("05bf184")
+	assert.Equal(t, 0, redirectionData.Hits)
+}
+
+func TestURLHandler_Preview_ShouldNotRevealDestinationOfPasswordProtectedLink(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{Password: "s3cret"})
+
+	resp := httptest.NewRecorder()
+	handler.Preview(resp, httptest.NewRequest(http.MethodGet, "/05bf184+", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.NotContains(t, resp.Body.String(), longURL)
+	assert.Contains(t, resp.Body.String(), `action="/05bf184"`)
+}
+
+func TestURLHandler_Preview_ShouldNotRevealDestinationOfLinkNotActiveYet(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc, NotYetActiveURL: "https://www.yemeksepeti.com/soon"}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{NotBefore: time.Now().Add(time.Hour)})
+
+	resp := httptest.NewRecorder()
+	handler.Preview(resp, httptest.NewRequest(http.MethodGet, "/05bf184+", nil))
+
+	assert.Equal(t, http.StatusFound, resp.Code)
+	assert.Equal(t, "https://www.yemeksepeti.com/soon", resp.Header().Get("Location"))
+	assert.NotContains(t, resp.Body.String(), longURL)
+}
+
+func TestURLHandler_Preview_ShouldReturnNotFoundWhenLinkDoesNotExist(t *testing.T) {
+	handler := URLHandler{ShortenerService: service.Shortener{DB: db.NewInMemoryDB()}}
+
+	resp := httptest.NewRecorder()
+	handler.Preview(resp, httptest.NewRequest(http.MethodGet, "/05bf184+", nil))
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+// TestURLHandler_Expand_ShouldShowInterstitialBeforeRedirecting tests integration of the interstitial
+func TestURLHandler_Expand_ShouldShowInterstitialBeforeRedirecting(t *testing.T) {
+	InMemoryDB := db.NewInMemoryDB()
+	svc := service.Shortener{DB: InMemoryDB, ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{Interstitial: true, Passthrough: model.Passthrough{Query: true}})
+
+	resp := httptest.NewRecorder()
+	handler.Expand(resp, httptest.NewRequest(http.MethodGet, "/05bf184?x=1", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), "This link goes to https://www.yemeksepeti.com/istanbul?x=1")
+	assert.Contains(t, resp.Body.String(), `<a href="/05bf184?confirm=1&amp;x=1" role="button">Continue</a>`)
+	redirectionData, _ := InMemoryDB.Get("05bf184")
+	assert.Equal(t, 0, redirectionData.Hits)
+
+	resp = httptest.NewRecorder()
+	handler.Expand(resp, httptest.NewRequest(http.MethodGet, "/05bf184?confirm=1&x=1", nil))
+
+	assert.Equal(t, http.StatusFound, resp.Code)
+	assert.Equal(t, "https://www.yemeksepeti.com/istanbul?x=1", resp.Header().Get("Location"))
+	redirectionData, _ = InMemoryDB.Get("05bf184")
+	assert.Equal(t, 1, redirectionData.Hits)
+}
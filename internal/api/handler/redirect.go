@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"net/http"
+	"net/url"
+)
+
+// permanentCacheControl lets the clients and the shared caches keep the permanent redirects for a day, so that
+// the destination can still be changed without waiting for the caches too long.
+const permanentCacheControl = "public, max-age=86400"
+
+// uncacheableCacheControl keeps the permanent redirects of the links which depend on the visitor or are limited
+// out of every cache, so that one visitor's destination is not served to the others and every hit is counted.
+const uncacheableCacheControl = "private, no-store"
+
+// redirectStatusCodes are the HTTP status codes of the redirect modes which redirect with a status code.
+var redirectStatusCodes = map[string]int{
+	model.RedirectMovedPermanently:  http.StatusMovedPermanently,
+	model.RedirectFound:             http.StatusFound,
+	model.RedirectSeeOther:          http.StatusSeeOther,
+	model.RedirectTemporaryRedirect: http.StatusTemporaryRedirect,
+	model.RedirectPermanentRedirect: http.StatusPermanentRedirect,
+}
+
+// redirect redirects the visitor to the destination in the redirect mode of the link, or in the default mode
+// if the link has none. 302 is used when the mode is unknown, and when the HTML page would redirect to a URL
+// which is not http or https since it would run in the origin of the short URL domain.
+func (h URLHandler) redirect(w http.ResponseWriter, r *http.Request, redirection model.Redirection) {
+	mode := redirection.Mode
+	if mode == "" {
+		mode = h.DefaultRedirectMode
+	}
+
+	if (mode == model.RedirectMetaRefresh || mode == model.RedirectJS) && isWebURL(redirection.URL) {
+		w.Header().Set("Cache-Control", "no-store")
+		h.html(w, http.StatusOK, redirectPageTemplate, redirectPage{URL: redirection.URL, Script: mode == model.RedirectJS})
+		return
+	}
+
+	statusCode, ok := redirectStatusCodes[mode]
+	if !ok {
+		statusCode = http.StatusFound
+	}
+	if statusCode == http.StatusMovedPermanently || statusCode == http.StatusPermanentRedirect {
+		cacheControl := uncacheableCacheControl
+		if redirection.Cacheable && redirection.Variant == "" {
+			cacheControl = permanentCacheControl
+		}
+		w.Header().Set("Cache-Control", cacheControl)
+	}
+	http.Redirect(w, r, redirection.URL, statusCode)
+}
+
+// validRedirectMode reports whether the given mode is empty or one of the redirect modes.
+func validRedirectMode(mode string) bool {
+	return mode == "" || model.IsRedirectMode(mode)
+}
+
+// isWebURL reports whether the given URL is an absolute http or https URL.
+func isWebURL(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https")
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestURLHandler_Expand_ShouldRedirectInRedirectMode(t *testing.T) {
+	tests := []struct {
+		name                 string
+		mode                 string
+		defaultMode          string
+		dynamic              bool
+		variant              string
+		url                  string
+		expectedStatus       int
+		expectedLocation     string
+		expectedCacheControl string
+		expectedBody         string
+	}{
+		{name: "default", url: longURL, expectedStatus: http.StatusFound, expectedLocation: longURL},
+		{name: "configured default", defaultMode: model.RedirectTemporaryRedirect, url: longURL, expectedStatus: http.StatusTemporaryRedirect, expectedLocation: longURL},
+		{name: "link mode wins over default", mode: model.RedirectSeeOther, defaultMode: model.RedirectTemporaryRedirect, url: longURL, expectedStatus: http.StatusSeeOther, expectedLocation: longURL},
+		{name: "moved permanently", mode: model.RedirectMovedPermanently, url: longURL, expectedStatus: http.StatusMovedPermanently, expectedLocation: longURL, expectedCacheControl: permanentCacheControl},
+		{name: "permanent redirect", mode: model.RedirectPermanentRedirect, url: longURL, expectedStatus: http.StatusPermanentRedirect, expectedLocation: longURL, expectedCacheControl: permanentCacheControl},
+		{name: "dynamic moved permanently", mode: model.RedirectMovedPermanently, dynamic: true, url: longURL, expectedStatus: http.StatusMovedPermanently, expectedLocation: longURL, expectedCacheControl: uncacheableCacheControl},
+		{name: "dynamic permanent redirect", mode: model.RedirectPermanentRedirect, dynamic: true, url: longURL, expectedStatus: http.StatusPermanentRedirect, expectedLocation: longURL, expectedCacheControl: uncacheableCacheControl},
+		{name: "permanent redirect with variant cookie", mode: model.RedirectPermanentRedirect, variant: "b", url: longURL, expectedStatus: http.StatusPermanentRedirect, expectedLocation: longURL, expectedCacheControl: uncacheableCacheControl},
+		{name: "unknown default", defaultMode: "300", url: longURL, expectedStatus: http.StatusFound, expectedLocation: longURL},
+		{name: "meta refresh", mode: model.RedirectMetaRefresh, url: longURL + "?a=1&b=2", expectedStatus: http.StatusOK, expectedCacheControl: "no-store", expectedBody: `<meta http-equiv="refresh" content="0; url=https://www.yemeksepeti.com/istanbul?a=1&amp;b=2">`},
+		{name: "javascript", mode: model.RedirectJS, url: longURL, expectedStatus: http.StatusOK, expectedCacheControl: "no-store", expectedBody: `<script>window.location.replace("https://www.yemeksepeti.com/istanbul");</script>`},
+		{name: "javascript with unsafe url", mode: model.RedirectJS, url: "javascript:alert(1)", expectedStatus: http.StatusFound, expectedLocation: "javascript:alert(1)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			mockShortenerService := mocks.NewMockShortenerService(controller)
+			mockShortenerService.EXPECT().Expand(gomock.Any()).Return(model.Redirection{URL: tt.url, Mode: tt.mode, Variant: tt.variant, Cacheable: !tt.dynamic}, nil).Times(1)
+
+			handler := URLHandler{ShortenerService: mockShortenerService, DefaultRedirectMode: tt.defaultMode}
+			resp := httptest.NewRecorder()
+			handler.Expand(resp, httptest.NewRequest(http.MethodGet, "/05bf184", nil))
+
+			assert.Equal(t, tt.expectedStatus, resp.Code)
+			assert.Equal(t, tt.expectedLocation, resp.Header().Get("Location"))
+			assert.Equal(t, tt.expectedCacheControl, resp.Header().Get("Cache-Control"))
+			assert.Contains(t, resp.Body.String(), tt.expectedBody)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"errors"
+	"net/http"
+	"time"
+)
+
+const errInvalidStatsTime = "from and to must be RFC 3339 times"
+
+// Stats returns the hit series of a short URL in the range and the granularity of the from, to and granularity query parameters.
+func (h URLHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	ws, err := workspace(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query, err := statsQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	stats, err := h.ShortenerService.Stats(ws, linkDomain(r), pathHash(r.URL.Path), query)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatusCode(err, http.StatusNotFound))
+		return
+	}
+
+	h.json(w, http.StatusOK, &stats)
+}
+
+// statsQuery parses the stats query from the query parameters, the parameters which are not given are left zero.
+func statsQuery(r *http.Request) (model.StatsQuery, error) {
+	values := r.URL.Query()
+	query := model.StatsQuery{Granularity: values.Get("granularity")}
+	for name, t := range map[string]*time.Time{"from": &query.From, "to": &query.To} {
+		value := values.Get(name)
+		if value == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return model.StatsQuery{}, errors.New(errInvalidStatsTime)
+		}
+		*t = parsed
+	}
+	return query, nil
+}
@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/api/service"
+	"dh-url-shortener/internal/platform/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestURLHandler_Stats tests integration of the hit series
+func TestURLHandler_Stats_ShouldReturnHitSeries(t *testing.T) {
+	now := time.Date(2022, 6, 2, 9, 30, 0, 0, time.UTC)
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain, Clock: func() time.Time { return now }}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{})
+	handler.Expand(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/05bf184", nil))
+	req := httptest.NewRequest(http.MethodGet, "/05bf184", nil)
+	req.Header.Set("Referer", "https://www.google.com/")
+	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 15_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.5 Mobile/15E148 Safari/604.1")
+	handler.Expand(httptest.NewRecorder(), req)
+
+	resp := httptest.NewRecorder()
+	handler.Stats(resp, httptest.NewRequest(http.MethodGet, "/links/05bf184/stats?granularity=minute&from=2022-06-02T09:29:00Z&to=2022-06-02T09:30:00Z", nil))
+
+	var stats model.Stats
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &stats))
+	assert.Equal(t, model.Stats{
+		Hash:        "05bf184",
+		Granularity: model.GranularityMinute,
+		From:        now.Add(-time.Minute),
+		To:          now.Add(time.Minute),
+		Hits:        2,
+		Series:      []model.StatsBucket{{Time: now.Add(-time.Minute)}, {Time: now, Hits: 2}},
+		Breakdown: model.Breakdown{
+			Referrers: []model.BreakdownEntry{{Value: model.ReferrerDirect, Hits: 1}, {Value: "www.google.com", Hits: 1}},
+			Browsers:  []model.BreakdownEntry{{Value: model.UserAgentOther, Hits: 1}, {Value: model.BrowserSafari, Hits: 1}},
+			OS:        []model.BreakdownEntry{{Value: model.OSIOS, Hits: 1}, {Value: model.UserAgentOther, Hits: 1}},
+			Devices:   []model.BreakdownEntry{{Value: model.DeviceMobile, Hits: 1}, {Value: model.UserAgentOther, Hits: 1}},
+		},
+	}, stats)
+}
+
+// TestURLHandler_Stats tests integration of the unique visitors
+func TestURLHandler_Stats_ShouldEstimateUniqueVisitors(t *testing.T) {
+	now := time.Date(2022, 6, 2, 9, 30, 0, 0, time.UTC)
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain, Visitors: service.NewFingerprinter([]byte("secret")), Clock: func() time.Time { return now }}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{})
+	for _, remoteAddr := range []string{"203.0.113.7:1234", "203.0.113.7:5678", "203.0.113.8:1234"} {
+		req := httptest.NewRequest(http.MethodGet, "/05bf184", nil)
+		req.RemoteAddr = remoteAddr
+		handler.Expand(httptest.NewRecorder(), req)
+	}
+
+	resp := httptest.NewRecorder()
+	handler.Stats(resp, httptest.NewRequest(http.MethodGet, "/links/05bf184/stats?granularity=day", nil))
+
+	var stats model.Stats
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &stats))
+	assert.Equal(t, 3, stats.Hits)
+	assert.Equal(t, 2, stats.Visitors)
+	assert.Equal(t, model.StatsBucket{Time: now.Truncate(24 * time.Hour), Hits: 3, Visitors: 2}, stats.Series[len(stats.Series)-1])
+
+	resp = httptest.NewRecorder()
+	handler.List(resp, httptest.NewRequest(http.MethodGet, "/list", nil))
+	assert.Contains(t, resp.Body.String(), `"hits":3,"human_hits":3,"bot_hits":0,"visitors":2`)
+}
+
+func TestURLHandler_Stats_ShouldReturnBadRequestWhenQueryIsInvalid(t *testing.T) {
+	handler := URLHandler{ShortenerService: service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}}
+	tests := map[string]string{
+		"/links/05bf184/stats?from=yesterday":   errInvalidStatsTime + "\n",
+		"/links/05bf184/stats?to=2022-06-02":    errInvalidStatsTime + "\n",
+		"/links/05bf184/stats?granularity=week": "invalid stats range: unknown granularity \"week\"\n",
+	}
+
+	for target, expectedBody := range tests {
+		resp := httptest.NewRecorder()
+		handler.Stats(resp, httptest.NewRequest(http.MethodGet, target, nil))
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, target)
+		assert.Equal(t, expectedBody, resp.Body.String(), target)
+	}
+}
+
+func TestURLHandler_Stats_ShouldReturnNotFoundWhenLinkNotExists(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Stats("", "example.com", "05bf184", model.StatsQuery{}).Return(model.Stats{}, model.ErrLinkNotFound).Times(1)
+	handler := URLHandler{ShortenerService: mockShortenerService}
+
+	resp := httptest.NewRecorder()
+	handler.Stats(resp, httptest.NewRequest(http.MethodGet, "http://example.com/links/05bf184/stats", nil))
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultTopLinks     = 10
+	maxTopLinks         = 100
+	errInvalidTopN      = "n must be between 1 and 100"
+	errInvalidTopWindow = "window must be a positive duration such as 15m, 1h or 24h"
+)
+
+// Top returns the links of the workspace with the most hits for their lifetime, or in the sliding window of the window
+// query parameter, limited to the n query parameter.
+func (h URLHandler) Top(w http.ResponseWriter, r *http.Request) {
+	ws, err := workspace(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	n := defaultTopLinks
+	if value := query.Get("n"); value != "" {
+		if n, err = strconv.Atoi(value); err != nil || n < 1 || n > maxTopLinks {
+			http.Error(w, errInvalidTopN, http.StatusBadRequest)
+			return
+		}
+	}
+	var window time.Duration
+	if value := query.Get("window"); value != "" {
+		if window, err = time.ParseDuration(value); err != nil || window <= 0 {
+			http.Error(w, errInvalidTopWindow, http.StatusBadRequest)
+			return
+		}
+	}
+
+	top, err := h.ShortenerService.Top(ws, n, window)
+	if err != nil {
+		http.Error(w, err.Error(), errorStatusCode(err, http.StatusInternalServerError))
+		return
+	}
+
+	h.json(w, http.StatusOK, &top)
+}
@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/api/service"
+	"dh-url-shortener/internal/platform/db"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestURLHandler_Top tests integration of the top links
+func TestURLHandler_Top_ShouldReturnLinksWithMostHits(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain, TopLinks: service.NewTopLinks(10)}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{})
+	ankara, _ := svc.Shorten("https://www.yemeksepeti.com/ankara", model.LinkOptions{Metadata: model.Metadata{Title: "Ankara"}})
+	ankara = strings.TrimPrefix(ankara, shortURLDomain)
+	for _, path := range []string{"/05bf184", ankara, ankara} {
+		handler.Expand(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	for _, target := range []string{"/links/top?n=1", "/links/top?n=1&window=5m", "/links/top?n=1&window=24h"} {
+		resp := httptest.NewRecorder()
+		handler.Top(resp, httptest.NewRequest(http.MethodGet, target, nil))
+
+		assert.Equal(t, http.StatusOK, resp.Code, target)
+		assert.Equal(t, `[{"hash":"`+ankara[1:]+`","original_url":"https://www.yemeksepeti.com/ankara","title":"Ankara","hits":2}]`, resp.Body.String(), target)
+	}
+}
+
+func TestURLHandler_Top_ShouldUseDefaults(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Top("team-a", defaultTopLinks, time.Duration(0)).Return([]model.TopLink{}, nil).Times(1)
+	handler := URLHandler{ShortenerService: mockShortenerService}
+
+	req := httptest.NewRequest(http.MethodGet, "/links/top", nil)
+	req.Header.Set(workspaceHeader, "team-a")
+	resp := httptest.NewRecorder()
+	handler.Top(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "[]", resp.Body.String())
+}
+
+func TestURLHandler_Top_ShouldReturnBadRequestWhenQueryIsInvalid(t *testing.T) {
+	handler := URLHandler{ShortenerService: service.Shortener{DB: db.NewInMemoryDB(), TopLinks: service.NewTopLinks(10)}}
+	tests := map[string]string{
+		"/links/top?n=0":        errInvalidTopN + "\n",
+		"/links/top?n=101":      errInvalidTopN + "\n",
+		"/links/top?n=ten":      errInvalidTopN + "\n",
+		"/links/top?window=day": errInvalidTopWindow + "\n",
+		"/links/top?window=-1h": errInvalidTopWindow + "\n",
+		"/links/top?window=25h": "invalid stats range: window is longer than 24h0m0s\n",
+	}
+
+	for target, expectedBody := range tests {
+		resp := httptest.NewRecorder()
+		handler.Top(resp, httptest.NewRequest(http.MethodGet, target, nil))
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, target)
+		assert.Equal(t, expectedBody, resp.Body.String(), target)
+	}
+}
@@ -2,44 +2,113 @@ package handler
 
 import (
 	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/safehash"
+	"dh-url-shortener/internal/platform/useragent"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net"
 	"net/http"
 	"net/url"
+	"regexp"
+	"strings"
+	"time"
 )
 
 type URLHandler struct {
 	ShortenerService ShortenerService
+	// NotYetActiveURL is where the links which are not active yet are redirected to, 404 is returned when it is empty.
+	NotYetActiveURL string
+	// MaxBulkSize is the maximum number of items in a bulk shorten request, zero means unlimited.
+	MaxBulkSize int
+	// DomainRedirects are the redirect targets of the short URL domains keyed by their hosts.
+	DomainRedirects map[string]DomainRedirects
+	// Countries resolves the countries of the visitors, the country rules of the links are not used when it is nil.
+	Countries CountryResolver
+	// DefaultRedirectMode is the redirect mode of the links which are created without one, empty means 302.
+	DefaultRedirectMode string
+	// TrustedProxies are the networks of the proxies whose X-Forwarded-For headers are used to find the visitor IPs.
+	TrustedProxies []*net.IPNet
+	// Bots classifies the User-Agent headers of the bots, only the HEAD requests are counted as bot hits when it is nil.
+	Bots BotClassifier
+}
+
+// DomainRedirects are the redirect targets of a short URL domain for its root path and its unknown links.
+type DomainRedirects struct {
+	RootURL     string
+	NotFoundURL string
 }
 
 type ShortenerService interface {
-	Shorten(string) (string, error)
-	Expand(string) (string, error)
-	List() []model.ListData
+	Shorten(string, model.LinkOptions) (string, error)
+	Expand(model.ExpandRequest) (model.Redirection, error)
+	Unlock(model.ExpandRequest, string) (model.Redirection, error)
+	Preview(string, string) (model.Preview, error)
+	Suggest(string, string) (string, error)
+	List(model.ListFilter) []model.ListData
+	Update(string, string, string, model.LinkUpdate) error
+	History(string, string, string) ([]model.Revision, error)
+	RestoreRevision(string, string, string, int, string) error
+	Stats(string, string, string, model.StatsQuery) (model.Stats, error)
+	Top(string, int, time.Duration) ([]model.TopLink, error)
+	Subscribe(model.EventFilter) (<-chan model.HitEvent, func())
+}
+
+// BotClassifier classifies the User-Agent headers of the crawlers, the link unfurlers and the uptime checkers.
+type BotClassifier interface {
+	IsBot(string) bool
+}
+
+// CountryResolver resolves the ISO 3166-1 alpha-2 country code of an IP, the unknown IPs are resolved to empty.
+type CountryResolver interface {
+	Country(net.IP) string
 }
 
 const (
 	shortURLHashLength = 7
 	errInvalidURL      = "invalid url"
+	errMistypedHash    = "mistyped short url"
+	errInvalidMaxHits  = "max_hits cannot be negative"
+	errInvalidWindow   = "not_after must be later than not_before"
+	errInvalidConflict = "query_conflict must be one of link, request and append"
+	errInvalidRule     = "device rules must have a valid url and match on a known os, device or browser"
+	errInvalidCountry  = "country rules must have a two letter country code and a valid url"
+	errInvalidMode     = "redirect_mode must be one of 301, 302, 303, 307, 308, meta-refresh and js"
+	errInvalidVariants = "variants must have unique names of letters, digits, - and _, a valid url and a positive weight"
+
+	// variantCookieMaxAge is how long a visitor sticks to the variant it is assigned to.
+	variantCookieMaxAge = 90 * 24 * 60 * 60
+)
+
+var (
+	variantNameRe = regexp.MustCompile("^[a-zA-Z0-9_-]+$")
+	countryCodeRe = regexp.MustCompile("^[a-zA-Z]{2}$")
 )
 
 // Shorten handles requests which are aim to shorten long URL.
 func (h URLHandler) Shorten(w http.ResponseWriter, r *http.Request) {
-	var sr ShortenRequest
-	err := json.NewDecoder(r.Body).Decode(&sr)
+	ws, err := workspace(r)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
+	var sr ShortenRequest
+	if err = json.NewDecoder(r.Body).Decode(&sr); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	if err = sr.validate(); err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	shortURL, err := h.ShortenerService.Shorten(sr.URL)
+	options := sr.options()
+	options.Workspace = ws
+	shortURL, err := h.ShortenerService.Shorten(sr.URL, options)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), errorStatusCode(err, http.StatusInternalServerError))
 		return
 	}
 
@@ -47,28 +116,215 @@ func (h URLHandler) Shorten(w http.ResponseWriter, r *http.Request) {
 }
 
 // Expand expands the given short URL to its long URL.
+// The path after the hash and the query are passed to the service for the links which pass them through.
 func (h URLHandler) Expand(w http.ResponseWriter, r *http.Request) {
-	hash := r.URL.Path[1:]
-	if len(hash) != shortURLHashLength {
-		http.Error(w, errors.New("invalid hash").Error(), http.StatusBadRequest)
+	hash, pathSuffix := splitHashPath(r.URL.Path)
+	hash, ok := h.checkHash(w, r, hash)
+	if !ok {
 		return
 	}
 
-	longURL, err := h.ShortenerService.Expand(hash)
+	if r.URL.Query().Get(previewParam) == "1" {
+		h.preview(w, r, hash)
+		return
+	}
+
+	redirection, err := h.ShortenerService.Expand(h.expandRequest(r, hash, pathSuffix))
+	if errors.Is(err, model.ErrLinkNotFound) {
+		h.notFound(w, r, err.Error())
+		return
+	}
+	if errors.Is(err, model.ErrLinkNotActive) && h.NotYetActiveURL != "" {
+		http.Redirect(w, r, h.NotYetActiveURL, http.StatusFound)
+		return
+	}
+	if errors.Is(err, model.ErrPasswordRequired) {
+		h.html(w, http.StatusUnauthorized, passwordFormTemplate, passwordForm{Action: passwordAction(r)})
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), errorStatusCode(err, http.StatusNotFound))
 		return
 	}
 
-	http.Redirect(w, r, longURL, http.StatusFound)
+	setVariantCookie(w, hash, redirection.Variant)
+	if redirection.Interstitial {
+		h.interstitial(w, r, redirection.URL)
+		return
+	}
+	h.redirect(w, r, redirection)
+}
+
+// Unlock expands the given password protected short URL to its long URL when the posted password is correct.
+func (h URLHandler) Unlock(w http.ResponseWriter, r *http.Request) {
+	hash, pathSuffix := splitHashPath(r.URL.Path)
+	hash, ok := h.checkHash(w, r, hash)
+	if !ok {
+		return
+	}
+
+	req := h.expandRequest(r, hash, pathSuffix)
+	// the visitor who enters the password has already chosen to follow the link
+	req.Confirmed = true
+	redirection, err := h.ShortenerService.Unlock(req, r.PostFormValue("password"))
+	if errors.Is(err, model.ErrInvalidPassword) {
+		h.html(w, http.StatusUnauthorized, passwordFormTemplate, passwordForm{Action: passwordAction(r), Error: err.Error()})
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), errorStatusCode(err, http.StatusNotFound))
+		return
+	}
+
+	setVariantCookie(w, hash, redirection.Variant)
+	http.Redirect(w, r, redirection.URL, http.StatusSeeOther)
+}
+
+// passwordAction returns the URL which the password form of the request posts to. It is the requested short URL with its
+// path suffix and query, so that they are passed through once the link is unlocked, without the preview marks.
+func passwordAction(r *http.Request) string {
+	action := *r.URL
+	action.Path = strings.TrimSuffix(action.Path, "+")
+	action.RawPath = ""
+	if query := action.Query(); query.Get(previewParam) != "" {
+		query.Del(previewParam)
+		action.RawQuery = query.Encode()
+	}
+	return action.RequestURI()
+}
+
+// expandRequest creates the request to expand the short URL with the given hash from the HTTP request.
+// The confirm parameter of the interstitial page is not passed through to the destination.
+func (h URLHandler) expandRequest(r *http.Request, hash, pathSuffix string) model.ExpandRequest {
+	query := r.URL.Query()
+	confirmed := query.Get(confirmParam) == "1"
+	query.Del(confirmParam)
+
+	req := model.ExpandRequest{
+		Host:         r.Host,
+		Hash:         hash,
+		PathSuffix:   pathSuffix,
+		Query:        query,
+		Referrer:     r.Referer(),
+		UserAgent:    useragent.Parse(r.UserAgent()),
+		RawUserAgent: r.UserAgent(),
+		Country:      h.country(r),
+		Confirmed:    confirmed,
+		Bot:          r.Method == http.MethodHead || (h.Bots != nil && h.Bots.IsBot(r.UserAgent())),
+	}
+	if ip := h.clientIP(r); ip != nil {
+		req.ClientIP = ip.String()
+	}
+	if cookie, err := r.Cookie(variantCookieName(hash)); err == nil {
+		req.Variant = cookie.Value
+	}
+	return req
+}
+
+// setVariantCookie makes the visitor stick to the variant of the short URL with the given hash it is assigned to.
+func setVariantCookie(w http.ResponseWriter, hash, variant string) {
+	if variant == "" {
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     variantCookieName(hash),
+		Value:    variant,
+		Path:     "/" + hash,
+		MaxAge:   variantCookieMaxAge,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// variantCookieName returns the name of the cookie which keeps the variant of the short URL with the given hash.
+func variantCookieName(hash string) string {
+	return "variant_" + hash
+}
+
+// checkHash checks the hash of the short URL and writes the error response when it is not valid. The safe hashes are
+// lowercased, and the ones with a wrong check character are answered with the nearest hash which has a link, if any.
+func (h URLHandler) checkHash(w http.ResponseWriter, r *http.Request, hash string) (string, bool) {
+	if len(hash) != shortURLHashLength && len(hash) != safehash.Length {
+		http.Error(w, errors.New("invalid hash").Error(), http.StatusBadRequest)
+		return "", false
+	}
+	if len(hash) == shortURLHashLength {
+		return hash, true
+	}
+
+	hash = strings.ToLower(hash)
+	if safehash.Valid(hash) {
+		return hash, true
+	}
+	if suggestion, err := h.ShortenerService.Suggest(r.Host, hash); err == nil {
+		http.Error(w, fmt.Sprintf("%s, did you mean /%s?", errMistypedHash, suggestion), http.StatusNotFound)
+		return "", false
+	}
+	h.notFound(w, r, errMistypedHash)
+	return "", false
 }
 
-// List returns a list of all stored URLs with their hits.
-func (h URLHandler) List(w http.ResponseWriter, _ *http.Request) {
-	listData := h.ShortenerService.List()
+// splitHashPath splits the path of the short URL to its hash and the path after the hash.
+func splitHashPath(path string) (string, string) {
+	parts := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+// Root redirects the root path of the short URL domain to its root redirect target.
+func (h URLHandler) Root(w http.ResponseWriter, r *http.Request) {
+	if redirects, ok := h.DomainRedirects[strings.ToLower(r.Host)]; ok && redirects.RootURL != "" {
+		http.Redirect(w, r, redirects.RootURL, http.StatusFound)
+		return
+	}
+	h.NotFound(w, r)
+}
+
+// NotFound redirects the unknown paths of the short URL domain to its not found target.
+func (h URLHandler) NotFound(w http.ResponseWriter, r *http.Request) {
+	h.notFound(w, r, "404 page not found")
+}
+
+// notFound redirects to the not found target of the short URL domain of the request, or writes the message with 404 if there is none.
+func (h URLHandler) notFound(w http.ResponseWriter, r *http.Request, message string) {
+	if redirects, ok := h.DomainRedirects[strings.ToLower(r.Host)]; ok && redirects.NotFoundURL != "" {
+		http.Redirect(w, r, redirects.NotFoundURL, http.StatusFound)
+		return
+	}
+	http.Error(w, message, http.StatusNotFound)
+}
+
+// List returns a list of the stored URLs of the workspace with their hits, optionally filtered by the tag query parameter.
+func (h URLHandler) List(w http.ResponseWriter, r *http.Request) {
+	ws, err := workspace(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	listData := h.ShortenerService.List(model.ListFilter{Workspace: ws, Tag: r.URL.Query().Get("tag")})
 	h.json(w, http.StatusOK, &listData)
 }
 
+// errorStatusCode maps the known service errors to HTTP status codes, other errors are mapped to the fallback status code.
+func errorStatusCode(err error, fallback int) int {
+	var policyErr *model.PolicyViolationError
+	switch {
+	case errors.As(err, &policyErr):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, model.ErrHitLimitReached), errors.Is(err, model.ErrLinkExpired):
+		return http.StatusGone
+	case errors.Is(err, model.ErrTooManyAttempts):
+		return http.StatusTooManyRequests
+	case errors.Is(err, model.ErrRevisionNotFound), errors.Is(err, model.ErrUnknownDomain), errors.Is(err, model.ErrInvalidTemplate),
+		errors.Is(err, model.ErrInvalidStatsRange):
+		return http.StatusBadRequest
+	}
+	return fallback
+}
+
 // json encodes the given data to JSON and writes it to the response writer.
 func (h URLHandler) json(w http.ResponseWriter, statusCode int, data interface{}) {
 	resp, _ := json.Marshal(data)
@@ -79,7 +335,29 @@ func (h URLHandler) json(w http.ResponseWriter, statusCode int, data interface{}
 }
 
 type ShortenRequest struct {
-	URL string `json:"url"`
+	URL       string     `json:"url"`
+	MaxHits   int        `json:"max_hits"`
+	NotBefore *time.Time `json:"not_before"`
+	NotAfter  *time.Time `json:"not_after"`
+	Password  string     `json:"password"`
+	Title     string     `json:"title"`
+	Tags      []string   `json:"tags"`
+	Notes     string     `json:"notes"`
+	Domain    string     `json:"domain"`
+
+	Passthrough  *PassthroughRequest `json:"passthrough"`
+	Params       map[string]string   `json:"params"`
+	DeviceRules  []model.DeviceRule  `json:"device_rules"`
+	CountryRules []model.CountryRule `json:"country_rules"`
+	Variants     []model.Variant     `json:"variants"`
+	RedirectMode string              `json:"redirect_mode"`
+	Interstitial bool                `json:"interstitial"`
+}
+
+type PassthroughRequest struct {
+	Path          bool   `json:"path"`
+	Query         bool   `json:"query"`
+	QueryConflict string `json:"query_conflict"`
 }
 
 type ShortenResponse struct {
@@ -88,11 +366,116 @@ type ShortenResponse struct {
 
 // Validate validates the ShortenRequest.URL field is a valid URL
 func (r ShortenRequest) validate() error {
-	if r.URL == "" {
-		return errors.New(errInvalidURL)
-	} else if _, err := url.ParseRequestURI(r.URL); err != nil {
+	if err := validateURL(r.URL); err != nil {
 		return err
 	}
 
+	if r.MaxHits < 0 {
+		return errors.New(errInvalidMaxHits)
+	}
+
+	if r.NotBefore != nil && r.NotAfter != nil && !r.NotAfter.After(*r.NotBefore) {
+		return errors.New(errInvalidWindow)
+	}
+
+	if r.Passthrough != nil {
+		switch r.Passthrough.QueryConflict {
+		case "", model.QueryConflictLink, model.QueryConflictRequest, model.QueryConflictAppend:
+		default:
+			return errors.New(errInvalidConflict)
+		}
+	}
+
+	for _, rule := range r.DeviceRules {
+		if !validDeviceRule(rule) {
+			return errors.New(errInvalidRule)
+		}
+	}
+
+	for _, rule := range r.CountryRules {
+		if !countryCodeRe.MatchString(rule.Country) || validateURL(rule.URL) != nil {
+			return errors.New(errInvalidCountry)
+		}
+	}
+
+	if !validVariants(r.Variants) {
+		return errors.New(errInvalidVariants)
+	}
+
+	if !validRedirectMode(r.RedirectMode) {
+		return errors.New(errInvalidMode)
+	}
+
 	return nil
 }
+
+// validateURL validates the given string is a valid URL
+func validateURL(u string) error {
+	if u == "" {
+		return errors.New(errInvalidURL)
+	}
+	_, err := url.ParseRequestURI(u)
+	return err
+}
+
+// validDeviceRule reports whether the rule has a valid URL and matches on at least one known user agent value.
+func validDeviceRule(rule model.DeviceRule) bool {
+	if validateURL(rule.URL) != nil || rule.OS == "" && rule.Device == "" && rule.Browser == "" {
+		return false
+	}
+	return oneOf(rule.OS, "", model.OSIOS, model.OSAndroid, model.OSWindows, model.OSMacOS, model.OSLinux, model.OSChromeOS,
+		model.UserAgentOther) &&
+		oneOf(rule.Device, "", model.DeviceMobile, model.DeviceTablet, model.DeviceDesktop, model.UserAgentOther) &&
+		oneOf(rule.Browser, "", model.BrowserChrome, model.BrowserSafari, model.BrowserFirefox, model.BrowserEdge, model.BrowserOpera,
+			model.BrowserSamsung, model.UserAgentOther)
+}
+
+// validVariants reports whether the variants have unique valid names, valid URLs and positive weights.
+func validVariants(variants []model.Variant) bool {
+	names := make(map[string]bool, len(variants))
+	for _, variant := range variants {
+		if !variantNameRe.MatchString(variant.Name) || names[variant.Name] || validateURL(variant.URL) != nil || variant.Weight <= 0 {
+			return false
+		}
+		names[variant.Name] = true
+	}
+	return true
+}
+
+// oneOf reports whether the value is one of the given values.
+func oneOf(value string, values ...string) bool {
+	for _, v := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// options converts the optional fields of the ShortenRequest to model.LinkOptions
+func (r ShortenRequest) options() model.LinkOptions {
+	options := model.LinkOptions{
+		MaxHits:      r.MaxHits,
+		Password:     r.Password,
+		Domain:       r.Domain,
+		Params:       r.Params,
+		DeviceRules:  r.DeviceRules,
+		Variants:     r.Variants,
+		RedirectMode: r.RedirectMode,
+		Interstitial: r.Interstitial,
+		Metadata:     model.Metadata{Title: r.Title, Tags: r.Tags, Notes: r.Notes},
+	}
+	if r.NotBefore != nil {
+		options.NotBefore = r.NotBefore.UTC()
+	}
+	if r.NotAfter != nil {
+		options.NotAfter = r.NotAfter.UTC()
+	}
+	for _, rule := range r.CountryRules {
+		options.CountryRules = append(options.CountryRules, model.CountryRule{Country: strings.ToUpper(rule.Country), URL: rule.URL})
+	}
+	if r.Passthrough != nil {
+		options.Passthrough = model.Passthrough{Path: r.Passthrough.Path, Query: r.Passthrough.Query, QueryConflict: r.Passthrough.QueryConflict}
+	}
+	return options
+}
@@ -4,13 +4,18 @@ import (
 	"bytes"
 	"dh-url-shortener/internal/api/model"
 	"dh-url-shortener/internal/api/service"
+	"dh-url-shortener/internal/platform/botfilter"
 	"dh-url-shortener/internal/platform/db"
+	"dh-url-shortener/internal/platform/geoip"
 	"dh-url-shortener/internal/platform/snapshot"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"testing"
 	"time"
 
@@ -29,7 +34,7 @@ func TestShortenerHandler_Shorten_ShouldReturnBadRequestWhenShortenRequestIsNotC
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 	mockShortenerService := mocks.NewMockShortenerService(controller)
-	mockShortenerService.EXPECT().Shorten(gomock.Any()).Return("", nil).Times(0)
+	mockShortenerService.EXPECT().Shorten(gomock.Any(), gomock.Any()).Return("", nil).Times(0)
 
 	handler := URLHandler{
 		ShortenerService: mockShortenerService,
@@ -46,7 +51,7 @@ func TestShortenerHandler_Shorten_ShouldReturnBadRequestWhenShortenRequestIsNotV
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 	mockShortenerService := mocks.NewMockShortenerService(controller)
-	mockShortenerService.EXPECT().Shorten(gomock.Any()).Return("", nil).Times(0)
+	mockShortenerService.EXPECT().Shorten(gomock.Any(), gomock.Any()).Return("", nil).Times(0)
 
 	handler := URLHandler{ShortenerService: mockShortenerService}
 	resp := httptest.NewRecorder()
@@ -61,7 +66,7 @@ func TestShortenerHandler_Shorten_ShouldReturnInternalServerErrorWhenShortenerSe
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 	mockShortenerService := mocks.NewMockShortenerService(controller)
-	mockShortenerService.EXPECT().Shorten(gomock.Any()).Return("", errors.New("service error")).Times(1)
+	mockShortenerService.EXPECT().Shorten(gomock.Any(), gomock.Any()).Return("", errors.New("service error")).Times(1)
 
 	handler := URLHandler{ShortenerService: mockShortenerService}
 	resp := httptest.NewRecorder()
@@ -72,13 +77,30 @@ func TestShortenerHandler_Shorten_ShouldReturnInternalServerErrorWhenShortenerSe
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 }
 
+func TestShortenerHandler_Shorten_ShouldReturnUnprocessableEntityWhenDestinationIsNotAllowed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	policyErr := &model.PolicyViolationError{Host: "evil.com", Rule: "block evil.com"}
+	mockShortenerService.EXPECT().Shorten(gomock.Any(), gomock.Any()).Return("", policyErr).Times(1)
+
+	handler := URLHandler{ShortenerService: mockShortenerService}
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/shorten", bytes.NewReader([]byte(`{"url": "https://evil.com"}`)))
+
+	handler.Shorten(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "block evil.com")
+}
+
 func TestShortenerHandler_Shorten_ShortenedURL(t *testing.T) {
 	expectedShortenedURL := fmt.Sprintf(`{"url":"%s/tTeEsT"}`, shortURLDomain)
 	shortenedURL := shortURLDomain + "/tTeEsT"
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 	mockShortenerService := mocks.NewMockShortenerService(controller)
-	mockShortenerService.EXPECT().Shorten(gomock.Any()).Return(shortenedURL, nil).Times(1)
+	mockShortenerService.EXPECT().Shorten(gomock.Any(), gomock.Any()).Return(shortenedURL, nil).Times(1)
 
 	handler := URLHandler{ShortenerService: mockShortenerService}
 	resp := httptest.NewRecorder()
@@ -91,6 +113,8 @@ func TestShortenerHandler_Shorten_ShortenedURL(t *testing.T) {
 }
 
 func TestShortenRequest_validate(t *testing.T) {
+	now := time.Now()
+	yesterday := now.Add(-24 * time.Hour)
 	tests := []struct {
 		name    string
 		sr      ShortenRequest
@@ -111,6 +135,101 @@ func TestShortenRequest_validate(t *testing.T) {
 			sr:      ShortenRequest{URL: "https://yemeksepeti.com"},
 			wantErr: false,
 		},
+		{
+			name:    "max hits is negative",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", MaxHits: -1},
+			wantErr: true,
+		},
+		{
+			name:    "not after is before not before",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", NotBefore: &now, NotAfter: &yesterday},
+			wantErr: true,
+		},
+		{
+			name:    "not after is later than not before",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", NotBefore: &yesterday, NotAfter: &now},
+			wantErr: false,
+		},
+		{
+			name:    "query conflict is unknown",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", Passthrough: &PassthroughRequest{Query: true, QueryConflict: "merge"}},
+			wantErr: true,
+		},
+		{
+			name:    "query conflict is known",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", Passthrough: &PassthroughRequest{Query: true, QueryConflict: "append"}},
+			wantErr: false,
+		},
+		{
+			name:    "device rule has an unknown os",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", DeviceRules: []model.DeviceRule{{OS: "symbian", URL: "https://yemeksepeti.com/app"}}},
+			wantErr: true,
+		},
+		{
+			name:    "device rule has no condition",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", DeviceRules: []model.DeviceRule{{URL: "https://yemeksepeti.com/app"}}},
+			wantErr: true,
+		},
+		{
+			name:    "device rule has no url",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", DeviceRules: []model.DeviceRule{{OS: model.OSIOS}}},
+			wantErr: true,
+		},
+		{
+			name:    "device rule is valid",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", DeviceRules: []model.DeviceRule{{OS: model.OSAndroid, Device: model.DeviceMobile, URL: "https://yemeksepeti.com/app"}}},
+			wantErr: false,
+		},
+		{
+			name:    "variant names are not unique",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", Variants: []model.Variant{{Name: "a", URL: "https://yemeksepeti.com/a", Weight: 1}, {Name: "a", URL: "https://yemeksepeti.com/b", Weight: 1}}},
+			wantErr: true,
+		},
+		{
+			name:    "variant name is not a valid cookie value",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", Variants: []model.Variant{{Name: "a;b", URL: "https://yemeksepeti.com/a", Weight: 1}}},
+			wantErr: true,
+		},
+		{
+			name:    "variant weight is not positive",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", Variants: []model.Variant{{Name: "a", URL: "https://yemeksepeti.com/a", Weight: 0}}},
+			wantErr: true,
+		},
+		{
+			name:    "variants are valid",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", Variants: []model.Variant{{Name: "a", URL: "https://yemeksepeti.com/a", Weight: 70}, {Name: "b-2", URL: "https://yemeksepeti.com/b", Weight: 30}}},
+			wantErr: false,
+		},
+		{
+			name:    "country rule has an invalid country code",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", CountryRules: []model.CountryRule{{Country: "TUR", URL: "https://yemeksepeti.com/tr"}}},
+			wantErr: true,
+		},
+		{
+			name:    "country rule has no url",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", CountryRules: []model.CountryRule{{Country: "TR"}}},
+			wantErr: true,
+		},
+		{
+			name:    "country rule is valid",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", CountryRules: []model.CountryRule{{Country: "tr", URL: "https://yemeksepeti.com/tr"}}},
+			wantErr: false,
+		},
+		{
+			name:    "redirect mode is unknown",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", RedirectMode: "300"},
+			wantErr: true,
+		},
+		{
+			name:    "redirect mode is known",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", RedirectMode: model.RedirectMetaRefresh},
+			wantErr: false,
+		},
+		{
+			name:    "max hits is positive",
+			sr:      ShortenRequest{URL: "https://yemeksepeti.com", MaxHits: 1},
+			wantErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -150,7 +269,7 @@ func TestUrlHandler_Expand_ShouldReturnStatusNotFoundWhenServiceReturnsError(t *
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 	mockShortenerService := mocks.NewMockShortenerService(controller)
-	mockShortenerService.EXPECT().Expand(gomock.Any()).Return("", errors.New("hash not found")).Times(1)
+	mockShortenerService.EXPECT().Expand(gomock.Any()).Return(model.Redirection{}, errors.New("hash not found")).Times(1)
 
 	handler := URLHandler{ShortenerService: mockShortenerService}
 
@@ -161,11 +280,160 @@ func TestUrlHandler_Expand_ShouldReturnStatusNotFoundWhenServiceReturnsError(t *
 	assert.Equal(t, http.StatusNotFound, resp.Code)
 }
 
+func TestUrlHandler_Expand_ShouldReturnUnprocessableEntityWhenDestinationIsNotAllowed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Expand(gomock.Any()).Return(model.Redirection{}, &model.PolicyViolationError{Host: "evil.com", Rule: "block evil.com"}).Times(1)
+
+	handler := URLHandler{ShortenerService: mockShortenerService}
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/05bf184", nil)
+	handler.Expand(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestUrlHandler_Expand_ShouldReturnStatusGoneWhenHitLimitReached(t *testing.T) {
+	InMemoryDB := db.NewInMemoryDB()
+	svc := service.Shortener{DB: InMemoryDB, ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{MaxHits: 1})
+
+	firstResp := httptest.NewRecorder()
+	handler.Expand(firstResp, httptest.NewRequest(http.MethodGet, "/05bf184", nil))
+	secondResp := httptest.NewRecorder()
+	handler.Expand(secondResp, httptest.NewRequest(http.MethodGet, "/05bf184", nil))
+
+	assert.Equal(t, http.StatusFound, firstResp.Code)
+	assert.Equal(t, http.StatusGone, secondResp.Code)
+}
+
+func TestUrlHandler_Expand_ShouldReturnStatusGoneWhenLinkIsExpired(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Expand(gomock.Any()).Return(model.Redirection{}, model.ErrLinkExpired).Times(1)
+
+	handler := URLHandler{ShortenerService: mockShortenerService}
+	resp := httptest.NewRecorder()
+	handler.Expand(resp, httptest.NewRequest(http.MethodGet, "/05bf184", nil))
+
+	assert.Equal(t, http.StatusGone, resp.Code)
+}
+
+func TestUrlHandler_Expand_ShouldReturnStatusNotFoundWhenLinkIsNotActiveYet(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Expand(gomock.Any()).Return(model.Redirection{}, model.ErrLinkNotActive).Times(1)
+
+	handler := URLHandler{ShortenerService: mockShortenerService}
+	resp := httptest.NewRecorder()
+	handler.Expand(resp, httptest.NewRequest(http.MethodGet, "/05bf184", nil))
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+func TestUrlHandler_Expand_ShouldRedirectToNotYetActiveURLWhenLinkIsNotActiveYet(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Expand(gomock.Any()).Return(model.Redirection{}, model.ErrLinkNotActive).Times(1)
+
+	handler := URLHandler{ShortenerService: mockShortenerService, NotYetActiveURL: "https://www.yemeksepeti.com/soon"}
+	resp := httptest.NewRecorder()
+	handler.Expand(resp, httptest.NewRequest(http.MethodGet, "/05bf184", nil))
+
+	assert.Equal(t, http.StatusFound, resp.Code)
+	assert.Equal(t, "https://www.yemeksepeti.com/soon", resp.Header().Get("Location"))
+}
+
+func TestUrlHandler_Expand_ShouldRenderPasswordFormWhenLinkIsPasswordProtected(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().Expand(gomock.Any()).Return(model.Redirection{}, model.ErrPasswordRequired).Times(1)
+
+	handler := URLHandler{ShortenerService: mockShortenerService}
+	resp := httptest.NewRecorder()
+	handler.Expand(resp, httptest.NewRequest(http.MethodGet, "/05bf184", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Equal(t, "text/html; charset=utf-8", resp.Header().Get("Content-Type"))
+	assert.Contains(t, resp.Body.String(), `action="/05bf184"`)
+}
+
+// TestURLHandler_Unlock tests integration of password protected link expansion
+func TestURLHandler_Unlock(t *testing.T) {
+	InMemoryDB := db.NewInMemoryDB()
+	svc := service.Shortener{DB: InMemoryDB, ShortURLDomain: shortURLDomain, PasswordAttempts: service.NewAttemptLimiter(1, time.Minute)}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{Password: "s3cret"})
+
+	unlock := func(password string) *httptest.ResponseRecorder {
+		resp := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/05bf184", strings.NewReader(url.Values{"password": {password}}.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		handler.Unlock(resp, req)
+		return resp
+	}
+
+	resp := unlock("s3cret")
+	assert.Equal(t, http.StatusSeeOther, resp.Code)
+	assert.Equal(t, longURL, resp.Header().Get("Location"))
+
+	resp = unlock("wrong")
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Body.String(), model.ErrInvalidPassword.Error())
+
+	resp = unlock("s3cret")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+}
+
+// TestURLHandler_Unlock should pass the path suffix and the query of the short URL through once the link is unlocked
+func TestURLHandler_Unlock_ShouldKeepPathSuffixAndQuery(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{Password: "s3cret", Passthrough: model.Passthrough{Path: true, Query: true}})
+
+	resp := httptest.NewRecorder()
+	handler.Expand(resp, httptest.NewRequest(http.MethodGet, "/05bf184/menu?x=1", nil))
+	assert.Equal(t, http.StatusUnauthorized, resp.Code)
+	assert.Contains(t, resp.Body.String(), `action="/05bf184/menu?x=1"`)
+
+	resp = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/05bf184/menu?x=1", strings.NewReader(url.Values{"password": {"s3cret"}}.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	handler.Unlock(resp, req)
+	assert.Equal(t, http.StatusSeeOther, resp.Code)
+	assert.Equal(t, longURL+"/menu?x=1", resp.Header().Get("Location"))
+}
+
+func TestPasswordAction(t *testing.T) {
+	tests := []struct {
+		target   string
+		expected string
+	}{
+		{target: "/05bf184", expected: "/05bf184"},
+		{target: "/05bf184+", expected: "/05bf184"},
+		{target: "/05bf184?preview=1&x=1", expected: "/05bf184?x=1"},
+		{target: "/05bf184/a%20b?y=2&x=1", expected: "/05bf184/a%20b?y=2&x=1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			assert.Equal(t, tt.expected, passwordAction(httptest.NewRequest(http.MethodGet, tt.target, nil)))
+		})
+	}
+}
+
 func TestUrlHandler_Expand_ShouldReturnStatusFoundWhenServiceReturnsLongURL(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 	mockShortenerService := mocks.NewMockShortenerService(controller)
-	mockShortenerService.EXPECT().Expand(gomock.Any()).Return(longURL, nil).Times(1)
+	mockShortenerService.EXPECT().Expand(gomock.Any()).Return(model.Redirection{URL: longURL}, nil).Times(1)
 
 	handler := URLHandler{ShortenerService: mockShortenerService}
 
@@ -176,6 +444,264 @@ func TestUrlHandler_Expand_ShouldReturnStatusFoundWhenServiceReturnsLongURL(t *t
 	assert.Equal(t, http.StatusFound, resp.Code)
 }
 
+// TestURLHandler_Expand_ShouldResolveLinksByHost tests integration of the same hash on multiple short URL domains
+func TestURLHandler_Expand_ShouldCountBotHitsSeparately(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc, Bots: botfilter.New("Slackbot", "UptimeRobot")}
+	_, _ = svc.Shorten(longURL, model.LinkOptions{})
+	tests := []struct {
+		method    string
+		userAgent string
+	}{
+		{http.MethodGet, "Mozilla/5.0 (iPhone; CPU iPhone OS 15_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.5 Mobile/15E148 Safari/604.1"},
+		{http.MethodGet, "Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"},
+		{http.MethodGet, "Mozilla/5.0 (compatible; UptimeRobot/2.0; http://www.uptimerobot.com/)"},
+		{http.MethodHead, "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.5 Safari/605.1.15"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, "/05bf184", nil)
+		req.Header.Set("User-Agent", tt.userAgent)
+		resp := httptest.NewRecorder()
+		handler.Expand(resp, req)
+		assert.Equal(t, longURL, resp.Header().Get("Location"), tt.userAgent)
+	}
+
+	list := svc.List(model.ListFilter{})
+	assert.Len(t, list, 1)
+	assert.Equal(t, 4, list[0].Hits)
+	assert.Equal(t, 1, list[0].HumanHits)
+	assert.Equal(t, 3, list[0].BotHits)
+}
+
+func TestURLHandler_Expand_ShouldResolveLinksByHost(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: "https://a.co", ShortURLDomains: []string{"https://b.co"}}
+	handler := URLHandler{ShortenerService: svc}
+	aShortURL, _ := svc.Shorten(longURL, model.LinkOptions{})
+	bShortURL, _ := svc.Shorten(longURL, model.LinkOptions{Domain: "b.co"})
+	_ = svc.Update("", "b.co", "05bf184", model.LinkUpdate{URL: "https://www.yemeksepeti.com/ankara"})
+
+	aResp := httptest.NewRecorder()
+	handler.Expand(aResp, httptest.NewRequest(http.MethodGet, aShortURL, nil))
+	bResp := httptest.NewRecorder()
+	handler.Expand(bResp, httptest.NewRequest(http.MethodGet, bShortURL, nil))
+
+	assert.Equal(t, "https://a.co/05bf184", aShortURL)
+	assert.Equal(t, "https://b.co/05bf184", bShortURL)
+	assert.Equal(t, longURL, aResp.Header().Get("Location"))
+	assert.Equal(t, "https://www.yemeksepeti.com/ankara", bResp.Header().Get("Location"))
+}
+
+func TestURLHandler_Expand_ShouldRedirectToNotFoundURLOfDomainWhenLinkNotFound(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: "https://a.co", ShortURLDomains: []string{"https://b.co"}}
+	handler := URLHandler{ShortenerService: svc, DomainRedirects: map[string]DomainRedirects{"b.co": {NotFoundURL: "https://b.co/404"}}}
+
+	aResp := httptest.NewRecorder()
+	handler.Expand(aResp, httptest.NewRequest(http.MethodGet, "https://a.co/05bf184", nil))
+	bResp := httptest.NewRecorder()
+	handler.Expand(bResp, httptest.NewRequest(http.MethodGet, "https://b.co/05bf184", nil))
+
+	assert.Equal(t, http.StatusNotFound, aResp.Code)
+	assert.Equal(t, http.StatusFound, bResp.Code)
+	assert.Equal(t, "https://b.co/404", bResp.Header().Get("Location"))
+}
+
+// TestURLHandler_Expand_ShouldPassPathSuffixAndQueryThrough tests integration of the passthrough links
+func TestURLHandler_Expand_ShouldPassPathSuffixAndQueryThrough(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	body := `{"url": "https://www.yemeksepeti.com/istanbul?lang=en", "passthrough": {"path": true, "query": true, "query_conflict": "request"}}`
+	resp := httptest.NewRecorder()
+	handler.Shorten(resp, httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(body)))
+	var shortenResp ShortenResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &shortenResp)
+
+	resp = httptest.NewRecorder()
+	handler.Expand(resp, httptest.NewRequest(http.MethodGet, shortenResp.URL+"/kadikoy/pizza?lang=tr&x=1", nil))
+
+	assert.Equal(t, http.StatusFound, resp.Code)
+	assert.Equal(t, "https://www.yemeksepeti.com/istanbul/kadikoy/pizza?lang=tr&x=1", resp.Header().Get("Location"))
+}
+
+// TestURLHandler_Expand_ShouldRenderParamTemplates tests integration of the param templates
+func TestURLHandler_Expand_ShouldRenderParamTemplates(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	body := `{"url": "https://www.yemeksepeti.com/istanbul", "params": {"utm_source": "{{referrer_host}}", "utm_medium": "social"}}`
+	resp := httptest.NewRecorder()
+	handler.Shorten(resp, httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(body)))
+	var shortenResp ShortenResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &shortenResp)
+
+	req := httptest.NewRequest(http.MethodGet, shortenResp.URL, nil)
+	req.Header.Set("Referer", "https://twitter.com/yemeksepeti")
+	resp = httptest.NewRecorder()
+	handler.Expand(resp, req)
+
+	assert.Equal(t, http.StatusFound, resp.Code)
+	assert.Equal(t, "https://www.yemeksepeti.com/istanbul?utm_medium=social&utm_source=twitter.com", resp.Header().Get("Location"))
+}
+
+func TestURLHandler_Shorten_ShouldReturnBadRequestWhenParamTemplateIsInvalid(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	body := `{"url": "https://www.yemeksepeti.com/istanbul", "params": {"utm_source": "{{source"}}`
+	resp := httptest.NewRecorder()
+	handler.Shorten(resp, httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+// TestURLHandler_Expand_ShouldRedirectByDeviceRules tests integration of the device rules
+func TestURLHandler_Expand_ShouldRedirectByDeviceRules(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc}
+	body := `{"url": "https://www.yemeksepeti.com/", "device_rules": [{"os": "ios", "url": "https://apps.apple.com/app/id1"}, {"os": "android", "url": "https://play.google.com/store/apps/details?id=app"}]}`
+	resp := httptest.NewRecorder()
+	handler.Shorten(resp, httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(body)))
+	var shortenResp ShortenResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &shortenResp)
+
+	tests := map[string]string{
+		"Mozilla/5.0 (iPhone; CPU iPhone OS 15_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.5 Mobile/15E148 Safari/604.1": "https://apps.apple.com/app/id1",
+		"Mozilla/5.0 (Linux; Android 12; Pixel 6) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/102.0.5005.78 Mobile Safari/537.36":               "https://play.google.com/store/apps/details?id=app",
+		"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/102.0.5005.63 Safari/537.36":                     "https://www.yemeksepeti.com/",
+	}
+	for userAgent, expected := range tests {
+		req := httptest.NewRequest(http.MethodGet, shortenResp.URL, nil)
+		req.Header.Set("User-Agent", userAgent)
+		resp = httptest.NewRecorder()
+		handler.Expand(resp, req)
+
+		assert.Equal(t, http.StatusFound, resp.Code)
+		assert.Equal(t, expected, resp.Header().Get("Location"))
+	}
+}
+
+// TestURLHandler_Expand_ShouldStickToAssignedVariant tests integration of the variants
+func TestURLHandler_Expand_ShouldStickToAssignedVariant(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain, Random: func(n int) int { return n - 1 }}
+	handler := URLHandler{ShortenerService: svc}
+	body := `{"url": "https://www.yemeksepeti.com/", "variants": [{"name": "a", "url": "https://www.yemeksepeti.com/a", "weight": 70}, {"name": "b", "url": "https://www.yemeksepeti.com/b", "weight": 30}]}`
+	resp := httptest.NewRecorder()
+	handler.Shorten(resp, httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(body)))
+	var shortenResp ShortenResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &shortenResp)
+
+	resp = httptest.NewRecorder()
+	handler.Expand(resp, httptest.NewRequest(http.MethodGet, shortenResp.URL, nil))
+
+	assert.Equal(t, http.StatusFound, resp.Code)
+	assert.Equal(t, "https://www.yemeksepeti.com/b", resp.Header().Get("Location"))
+	cookies := resp.Result().Cookies()
+	assert.Len(t, cookies, 1)
+	assert.Equal(t, "b", cookies[0].Value)
+
+	req := httptest.NewRequest(http.MethodGet, shortenResp.URL, nil)
+	cookies[0].Value = "a"
+	req.AddCookie(cookies[0])
+	resp = httptest.NewRecorder()
+	handler.Expand(resp, req)
+
+	assert.Equal(t, "https://www.yemeksepeti.com/a", resp.Header().Get("Location"))
+
+	list := svc.List(model.ListFilter{})
+	assert.Equal(t, 2, list[0].Hits)
+	assert.Equal(t, 1, list[0].Variants[0].Hits)
+	assert.Equal(t, 1, list[0].Variants[1].Hits)
+}
+
+// TestURLHandler_Expand_ShouldRedirectByCountryRules tests integration of the country rules
+func TestURLHandler_Expand_ShouldRedirectByCountryRules(t *testing.T) {
+	countries, _ := geoip.Parse(strings.NewReader("78.160.0.0,78.191.255.255,TR\n85.214.0.0/15,DE"))
+	_, proxies, _ := net.ParseCIDR("10.0.0.0/8")
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}
+	handler := URLHandler{ShortenerService: svc, Countries: countries, TrustedProxies: []*net.IPNet{proxies}}
+	body := `{"url": "https://www.yemeksepeti.com/", "country_rules": [{"country": "tr", "url": "https://www.yemeksepeti.com/tr"}], "params": {"c": "{{country}}"}}`
+	resp := httptest.NewRecorder()
+	handler.Shorten(resp, httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(body)))
+	var shortenResp ShortenResponse
+	_ = json.Unmarshal(resp.Body.Bytes(), &shortenResp)
+
+	tests := []struct {
+		remoteAddr   string
+		forwardedFor string
+		expected     string
+	}{
+		{remoteAddr: "78.170.1.1:5000", expected: "https://www.yemeksepeti.com/tr?c=TR"},
+		{remoteAddr: "10.0.0.1:5000", forwardedFor: "78.170.1.1", expected: "https://www.yemeksepeti.com/tr?c=TR"},
+		{remoteAddr: "85.214.1.1:5000", forwardedFor: "78.170.1.1", expected: "https://www.yemeksepeti.com/?c=DE"},
+		{remoteAddr: "1.0.0.1:5000", expected: "https://www.yemeksepeti.com/"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, shortenResp.URL, nil)
+		req.RemoteAddr = tt.remoteAddr
+		if tt.forwardedFor != "" {
+			req.Header.Set("X-Forwarded-For", tt.forwardedFor)
+		}
+		resp = httptest.NewRecorder()
+		handler.Expand(resp, req)
+
+		assert.Equal(t, http.StatusFound, resp.Code)
+		assert.Equal(t, tt.expected, resp.Header().Get("Location"))
+	}
+}
+
+// TestURLHandler_Expand_ShouldSuggestNearestHashWhenSafeHashIsMistyped tests integration of the safe hashes
+func TestURLHandler_Expand_ShouldSuggestNearestHashWhenSafeHashIsMistyped(t *testing.T) {
+	svc := service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain, SafeHashes: true}
+	handler := URLHandler{ShortenerService: svc}
+	shortURL, _ := svc.Shorten(longURL, model.LinkOptions{})
+	assert.Equal(t, shortURLDomain+"/kduf6s5h", shortURL)
+
+	tests := []struct {
+		path             string
+		expectedStatus   int
+		expectedLocation string
+		expectedBody     string
+	}{
+		{path: "/kduf6s5h", expectedStatus: http.StatusFound, expectedLocation: longURL},
+		{path: "/KDUF6S5H", expectedStatus: http.StatusFound, expectedLocation: longURL},
+		{path: "/kduf7s5h", expectedStatus: http.StatusNotFound, expectedBody: "mistyped short url, did you mean /kduf6s5h?\n"},
+		{path: "/kdfu6s5h", expectedStatus: http.StatusNotFound, expectedBody: "mistyped short url, did you mean /kduf6s5h?\n"},
+		{path: "/kduf6s50", expectedStatus: http.StatusNotFound, expectedBody: "mistyped short url, did you mean /kduf6s5h?\n"},
+		{path: "/23456788", expectedStatus: http.StatusNotFound, expectedBody: "mistyped short url\n"},
+	}
+	for _, tt := range tests {
+		resp := httptest.NewRecorder()
+		handler.Expand(resp, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		assert.Equal(t, tt.expectedStatus, resp.Code, tt.path)
+		assert.Equal(t, tt.expectedLocation, resp.Header().Get("Location"), tt.path)
+		if tt.expectedBody != "" {
+			assert.Equal(t, tt.expectedBody, resp.Body.String(), tt.path)
+		}
+	}
+}
+
+func TestURLHandler_Root(t *testing.T) {
+	handler := URLHandler{DomainRedirects: map[string]DomainRedirects{
+		"a.co": {RootURL: "https://www.a.com"},
+		"b.co": {NotFoundURL: "https://b.co/404"},
+	}}
+
+	tests := []struct {
+		url              string
+		expectedStatus   int
+		expectedLocation string
+	}{
+		{url: "https://A.co/", expectedStatus: http.StatusFound, expectedLocation: "https://www.a.com"},
+		{url: "https://b.co/", expectedStatus: http.StatusFound, expectedLocation: "https://b.co/404"},
+		{url: "https://c.co/", expectedStatus: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		resp := httptest.NewRecorder()
+		handler.Root(resp, httptest.NewRequest(http.MethodGet, tt.url, nil))
+		assert.Equal(t, tt.expectedStatus, resp.Code, tt.url)
+		assert.Equal(t, tt.expectedLocation, resp.Header().Get("Location"), tt.url)
+	}
+}
+
 func TestURLHandler_List(t *testing.T) {
 	testData := []model.ListData{
 		{
@@ -189,7 +715,7 @@ func TestURLHandler_List(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
 	mockShortenerService := mocks.NewMockShortenerService(controller)
-	mockShortenerService.EXPECT().List().Return(testData).Times(1)
+	mockShortenerService.EXPECT().List(model.ListFilter{}).Return(testData).Times(1)
 	handler := URLHandler{ShortenerService: mockShortenerService}
 	resp := httptest.NewRecorder()
 	req := httptest.NewRequest(http.MethodGet, "/list", nil)
@@ -199,6 +725,19 @@ func TestURLHandler_List(t *testing.T) {
 	assert.Equal(t, string(expectedResp), resp.Body.String())
 }
 
+func TestURLHandler_List_ShouldFilterByTag(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockShortenerService := mocks.NewMockShortenerService(controller)
+	mockShortenerService.EXPECT().List(model.ListFilter{Tag: "campaign"}).Return([]model.ListData{}).Times(1)
+	handler := URLHandler{ShortenerService: mockShortenerService}
+	resp := httptest.NewRecorder()
+	handler.List(resp, httptest.NewRequest(http.MethodGet, "/list?tag=campaign", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "[]", resp.Body.String())
+}
+
 func BenchmarkURLHandler_Expand(b *testing.B) {
 	inMemoryDB := db.NewInMemoryDB()
 	shortenerService := service.Shortener{DB: inMemoryDB, ShortURLDomain: "http://localhost:8080"}
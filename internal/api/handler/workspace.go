@@ -0,0 +1,26 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+const (
+	// workspaceHeader is the header which carries the workspace of the caller, it is expected to be set by the
+	// gateway which authenticates the caller.
+	workspaceHeader     = "X-Workspace"
+	errInvalidWorkspace = "X-Workspace must be at most 64 letters, digits, - and _"
+)
+
+var workspaceRe = regexp.MustCompile("^[a-z0-9_-]{0,64}$")
+
+// workspace returns the lowercase workspace of the request from the X-Workspace header, empty means the default workspace.
+func workspace(r *http.Request) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(r.Header.Get(workspaceHeader)))
+	if !workspaceRe.MatchString(name) {
+		return "", errors.New(errInvalidWorkspace)
+	}
+	return name, nil
+}
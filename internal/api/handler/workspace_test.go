@@ -0,0 +1,83 @@
+package handler
+
+import (
+	"dh-url-shortener/internal/api/service"
+	"dh-url-shortener/internal/platform/db"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func shortenInWorkspace(t *testing.T, h URLHandler, ws string) string {
+	req := httptest.NewRequest(http.MethodPost, "/shorten", strings.NewReader(`{"url":"`+longURL+`"}`))
+	req.Header.Set(workspaceHeader, ws)
+	resp := httptest.NewRecorder()
+	h.Shorten(resp, req)
+	assert.Equal(t, http.StatusCreated, resp.Code)
+
+	var sr ShortenResponse
+	assert.Nil(t, json.Unmarshal(resp.Body.Bytes(), &sr))
+	return strings.TrimPrefix(sr.URL, shortURLDomain)
+}
+
+// TestURLHandler_Workspaces tests integration of the workspaces
+func TestURLHandler_Workspaces_ShouldIsolateLinksOfWorkspaces(t *testing.T) {
+	h := URLHandler{ShortenerService: service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}}
+	teamA := shortenInWorkspace(t, h, "Team-A")
+	teamB := shortenInWorkspace(t, h, "team-b")
+	assert.NotEqual(t, teamA, teamB)
+	assert.Equal(t, teamA, shortenInWorkspace(t, h, "team-a"))
+
+	list := httptest.NewRequest(http.MethodGet, "/list", nil)
+	list.Header.Set(workspaceHeader, "team-a")
+	resp := httptest.NewRecorder()
+	h.List(resp, list)
+	assert.Contains(t, resp.Body.String(), teamA[1:])
+	assert.NotContains(t, resp.Body.String(), teamB[1:])
+
+	resp = httptest.NewRecorder()
+	h.List(resp, httptest.NewRequest(http.MethodGet, "/list", nil))
+	assert.Equal(t, "[]", resp.Body.String())
+
+	update := httptest.NewRequest(http.MethodPut, "/links"+teamA, strings.NewReader(`{"url":"https://www.yemeksepeti.com/ankara"}`))
+	update.Header.Set(workspaceHeader, "team-b")
+	resp = httptest.NewRecorder()
+	h.Update(resp, update)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	history := httptest.NewRequest(http.MethodGet, "/links"+teamA+"/history", nil)
+	resp = httptest.NewRecorder()
+	h.History(resp, history)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	h.Expand(resp, httptest.NewRequest(http.MethodGet, teamA, nil))
+	assert.Equal(t, http.StatusFound, resp.Code)
+	assert.Equal(t, longURL, resp.Header().Get("Location"))
+}
+
+func TestURLHandler_Workspaces_ShouldReturnBadRequestWhenWorkspaceIsInvalid(t *testing.T) {
+	h := URLHandler{ShortenerService: service.Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: shortURLDomain}}
+	handlers := map[string]http.HandlerFunc{
+		"shorten": h.Shorten,
+		"bulk":    h.BulkShorten,
+		"list":    h.List,
+		"update":  h.Update,
+		"history": h.History,
+		"restore": h.RestoreRevision,
+	}
+
+	for name, handle := range handlers {
+		req := httptest.NewRequest(http.MethodPost, "/links/05bf184", strings.NewReader(`{"url":"`+longURL+`"}`))
+		req.Header.Set(workspaceHeader, "team a")
+		resp := httptest.NewRecorder()
+		handle(resp, req)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, name)
+		assert.Equal(t, errInvalidWorkspace+"\n", resp.Body.String(), name)
+	}
+}
@@ -0,0 +1,121 @@
+package model
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+)
+
+// ReferrerDirect is the referrer host of the hits without a referrer.
+const ReferrerDirect = "direct"
+
+// The capacities of the breakdowns. When a breakdown is full, the value with the least hits is replaced by the new value
+// which takes over its hits, so the values with the most hits are kept while the memory of a link stays flat. The hits
+// of the values which are added to a full breakdown are overestimated by at most the hits of the replaced value.
+const (
+	maxReferrers       = 50
+	maxUserAgentValues = 20
+)
+
+// HitBreakdown are the hit counts of a link by the referrer host, the browser, the OS and the device class of the visitors.
+type HitBreakdown struct {
+	Referrers map[string]int `json:",omitempty"`
+	Browsers  map[string]int `json:",omitempty"`
+	OS        map[string]int `json:",omitempty"`
+	Devices   map[string]int `json:",omitempty"`
+	mutex     sync.RWMutex
+}
+
+// Add adds the given hit to the breakdown.
+func (b *HitBreakdown) Add(hit Hit) {
+	referrer := hit.ReferrerHost
+	if referrer == "" {
+		referrer = ReferrerDirect
+	}
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.Referrers = addCapped(b.Referrers, referrer, maxReferrers)
+	b.Browsers = addCapped(b.Browsers, hit.UserAgent.Browser, maxUserAgentValues)
+	b.OS = addCapped(b.OS, hit.UserAgent.OS, maxUserAgentValues)
+	b.Devices = addCapped(b.Devices, hit.UserAgent.Device, maxUserAgentValues)
+}
+
+// MarshalJSON marshals the counts of the breakdown under its lock.
+func (b *HitBreakdown) MarshalJSON() ([]byte, error) {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return json.Marshal(struct {
+		Referrers map[string]int `json:",omitempty"`
+		Browsers  map[string]int `json:",omitempty"`
+		OS        map[string]int `json:",omitempty"`
+		Devices   map[string]int `json:",omitempty"`
+	}{b.Referrers, b.Browsers, b.OS, b.Devices})
+}
+
+// addCapped adds a hit of the given value to the counts, the counts are not changed for the empty value.
+// The value with the least hits is replaced when the counts are full, the smallest of them on ties.
+func addCapped(counts map[string]int, value string, capacity int) map[string]int {
+	if value == "" {
+		return counts
+	}
+	if counts == nil {
+		counts = make(map[string]int)
+	}
+
+	if _, ok := counts[value]; !ok && len(counts) >= capacity {
+		least := ""
+		for v, hits := range counts {
+			if least == "" || hits < counts[least] || hits == counts[least] && v < least {
+				least = v
+			}
+		}
+		counts[value] = counts[least]
+		delete(counts, least)
+	}
+	counts[value]++
+	return counts
+}
+
+// BreakdownEntry is a value of a breakdown with its hit count.
+type BreakdownEntry struct {
+	Value string `json:"value"`
+	Hits  int    `json:"hits"`
+}
+
+// Breakdown is the breakdown of the hits of a link, the values of each breakdown are in the descending order of their hits.
+type Breakdown struct {
+	Referrers []BreakdownEntry `json:"referrers"`
+	Browsers  []BreakdownEntry `json:"browsers"`
+	OS        []BreakdownEntry `json:"os"`
+	Devices   []BreakdownEntry `json:"devices"`
+}
+
+// Breakdown returns the values of the breakdowns in the descending order of their hits, the nil breakdown has no values.
+func (b *HitBreakdown) Breakdown() Breakdown {
+	if b == nil {
+		return (&HitBreakdown{}).Breakdown()
+	}
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return Breakdown{
+		Referrers: sortedEntries(b.Referrers),
+		Browsers:  sortedEntries(b.Browsers),
+		OS:        sortedEntries(b.OS),
+		Devices:   sortedEntries(b.Devices),
+	}
+}
+
+// sortedEntries returns the counts in the descending order of their hits, and in the order of their values on ties.
+func sortedEntries(counts map[string]int) []BreakdownEntry {
+	entries := make([]BreakdownEntry, 0, len(counts))
+	for value, hits := range counts {
+		entries = append(entries, BreakdownEntry{Value: value, Hits: hits})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Hits != entries[j].Hits {
+			return entries[i].Hits > entries[j].Hits
+		}
+		return entries[i].Value < entries[j].Value
+	})
+	return entries
+}
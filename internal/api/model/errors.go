@@ -0,0 +1,52 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrLinkNotFound is returned when there is no link with the given hash.
+var ErrLinkNotFound = errors.New("not found")
+
+// ErrUnknownDomain is returned when a link is created on a short URL domain which is not configured.
+var ErrUnknownDomain = errors.New("unknown short url domain")
+
+// ErrHitLimitReached is returned when a link has already been used as many times as its MaxHits allows.
+var ErrHitLimitReached = errors.New("link hit limit reached")
+
+// ErrLinkNotActive is returned when a link is expanded before its NotBefore time.
+var ErrLinkNotActive = errors.New("link is not active yet")
+
+// ErrPasswordRequired is returned when a password protected link is expanded without a password.
+var ErrPasswordRequired = errors.New("link is password protected")
+
+// ErrInvalidPassword is returned when a password protected link is expanded with a wrong password.
+var ErrInvalidPassword = errors.New("invalid password")
+
+// ErrTooManyAttempts is returned when a password protected link has too many failed attempts.
+var ErrTooManyAttempts = errors.New("too many failed attempts, try again later")
+
+// ErrRevisionNotFound is returned when a revision which does not exist is restored.
+var ErrRevisionNotFound = errors.New("revision not found")
+
+// ErrLinkExpired is returned when a link is expanded after its NotAfter time.
+var ErrLinkExpired = errors.New("link is expired")
+
+// ErrInvalidTemplate is returned when a link is created with a param template which can not be rendered.
+var ErrInvalidTemplate = errors.New("invalid param template")
+
+// ErrInvalidStatsRange is returned when the stats are requested with an unknown granularity or a range which is too long.
+var ErrInvalidStatsRange = errors.New("invalid stats range")
+
+// ErrHashesExhausted is returned when no free short URL hash is found within the allowed number of attempts.
+var ErrHashesExhausted = errors.New("no short url hash is available")
+
+// PolicyViolationError is returned when the destination host of a URL is rejected by the domain policy.
+type PolicyViolationError struct {
+	Host string
+	Rule string
+}
+
+func (e *PolicyViolationError) Error() string {
+	return fmt.Sprintf("destination %s is not allowed by policy rule %q", e.Host, e.Rule)
+}
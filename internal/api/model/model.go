@@ -1,12 +1,378 @@
 package model
 
+import (
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+)
+
 type RedirectionData struct {
-	OriginalURL string
-	Hits        int
+	OriginalURL  string
+	CanonicalURL string
+	Hits         int
+	// BotHits are the hits of the crawlers, the link unfurlers and the uptime checkers which are included in Hits.
+	BotHits   int
+	Revisions []Revision
+	// VariantHits are the hit counts of the variants keyed by their names.
+	VariantHits *HitCounts `json:",omitempty"`
+	// Series are the hits in time buckets, the hits which are made before the series are recorded are not in it.
+	Series *HitSeries `json:",omitempty"`
+	// Breakdown are the hits by the referrer and the user agent of the visitors, the hits which are made before the
+	// breakdown is recorded are not in it.
+	Breakdown *HitBreakdown `json:",omitempty"`
+	// Visitors is the HyperLogLog sketch of the fingerprints of all visitors.
+	Visitors []byte `json:",omitempty"`
+	// CreatedAt is the creation time of the link, it is zero for the links which are created before it is recorded.
+	CreatedAt time.Time
+	LinkOptions
+}
+
+// Hit is a redirect of a short link.
+type Hit struct {
+	// Variant is the name of the variant the visitor is redirected to, empty when the link has no variants.
+	Variant string
+	// Time is the time of the hit, the hit is not added to the series when it is zero.
+	Time time.Time
+	// ReferrerHost is the lowercase host of the referrer, empty when the visitor has no referrer.
+	ReferrerHost string
+	// UserAgent is the user agent of the visitor, the empty values of it are not added to the breakdown.
+	UserAgent UserAgent
+	// Visitor is the fingerprint of the visitor, zero when it is unknown.
+	Visitor uint64
+	// Bot is set when the hit is made by a bot instead of a human.
+	Bot bool
+}
+
+// Redirection is the result of expanding a short link.
+type Redirection struct {
+	URL string
+	// Variant is the name of the variant the visitor is assigned to, empty when the link has no variants.
+	Variant string
+	// Mode is the redirect mode of the link, empty means the default mode.
+	Mode string
+	// Interstitial is set when the visitor must confirm the destination before being redirected, the hit is not
+	// counted until the visitor confirms it.
+	Interstitial bool
+	// Cacheable is set when the destination is the same for every visitor and the link has no hit limit or expiry,
+	// so that the shared caches may keep a permanent redirect.
+	Cacheable bool
+}
+
+// Preview is the information about a short link which is shown to the visitors before they follow it.
+type Preview struct {
+	Hash      string
+	URL       string
+	Title     string
+	CreatedAt time.Time
+	Hits      int
+}
+
+// Redirect modes decide how the visitors are redirected to the destination, either with an HTTP status code
+// or with an HTML page which redirects with a meta refresh tag or JavaScript.
+const (
+	RedirectMovedPermanently  = "301"
+	RedirectFound             = "302"
+	RedirectSeeOther          = "303"
+	RedirectTemporaryRedirect = "307"
+	RedirectPermanentRedirect = "308"
+	RedirectMetaRefresh       = "meta-refresh"
+	RedirectJS                = "js"
+)
+
+// redirectModes are all of the redirect modes.
+var redirectModes = map[string]bool{
+	RedirectMovedPermanently:  true,
+	RedirectFound:             true,
+	RedirectSeeOther:          true,
+	RedirectTemporaryRedirect: true,
+	RedirectPermanentRedirect: true,
+	RedirectMetaRefresh:       true,
+	RedirectJS:                true,
+}
+
+// IsRedirectMode reports whether the given mode is one of the redirect modes.
+func IsRedirectMode(mode string) bool {
+	return redirectModes[mode]
+}
+
+// Revision is a change of the destination of a short link.
+type Revision struct {
+	Revision int       `json:"revision"`
+	Time     time.Time `json:"time"`
+	OldURL   string    `json:"old_url"`
+	NewURL   string    `json:"new_url"`
+	Actor    string    `json:"actor"`
+}
+
+// LinkOptions are the optional settings of a short link which are given on creation.
+type LinkOptions struct {
+	// MaxHits is the number of redirects after which the link is no longer usable, zero means unlimited.
+	MaxHits int
+	// NotBefore is the moment the link starts redirecting, zero means the link is active immediately.
+	NotBefore time.Time
+	// NotAfter is the moment the link stops redirecting, zero means the link never expires.
+	NotAfter time.Time
+	// Password is the plain text password on creation, it is replaced with its salted hash before the link is stored.
+	Password string
+	// Domain is the host of the short URL domain the link is created on, empty means the primary domain.
+	Domain string
+	// Workspace is the workspace the link belongs to, empty means the default workspace. The links can be listed
+	// and managed only in their own workspace, but they redirect on every request regardless of it.
+	Workspace string
+	// Passthrough defines how the path suffix and the query of the request are passed to the destination.
+	Passthrough Passthrough
+	// Params are the query parameters added to the destination on redirect, their values are templates like
+	// "{{referrer_host}}" which are rendered from the request.
+	Params map[string]string
+	// DeviceRules pick the destination by the user agent of the request, the first matching rule wins and
+	// the original URL is used when none of them matches.
+	DeviceRules []DeviceRule
+	// CountryRules pick the destination by the country of the visitor for the visitors which do not match a device rule.
+	CountryRules []CountryRule
+	// RedirectMode is one of the redirect modes, empty means the default mode.
+	RedirectMode string
+	// Interstitial shows a warning page which the visitors must confirm before they are redirected.
+	Interstitial bool
+	// Variants split the visitors which do not match a device rule across weighted destinations.
+	Variants []Variant
+	Metadata
+}
+
+// Variant is a destination of a split link, a visitor is assigned to it with the probability of its share of the total weight.
+type Variant struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Weight int    `json:"weight"`
+}
+
+// HitCounts are hit counts keyed by name.
+type HitCounts struct {
+	counts map[string]int
+	mutex  sync.RWMutex
+}
+
+// NewHitCounts creates hit counts with a copy of the given counts.
+func NewHitCounts(counts map[string]int) *HitCounts {
+	c := &HitCounts{counts: make(map[string]int, len(counts))}
+	for name, hits := range counts {
+		c.counts[name] = hits
+	}
+	return c
+}
+
+// Add adds a hit of the given name.
+func (c *HitCounts) Add(name string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if c.counts == nil {
+		c.counts = make(map[string]int)
+	}
+	c.counts[name]++
+}
+
+// Get returns the hits of the given name, the nil counts have no hits.
+func (c *HitCounts) Get(name string) int {
+	if c == nil {
+		return 0
+	}
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.counts[name]
+}
+
+// MarshalJSON marshals the counts as an object under their lock.
+func (c *HitCounts) MarshalJSON() ([]byte, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return json.Marshal(c.counts)
+}
+
+// UnmarshalJSON unmarshals the counts from an object.
+func (c *HitCounts) UnmarshalJSON(data []byte) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return json.Unmarshal(data, &c.counts)
+}
+
+// VariantStats is a variant of a split link with its hit count.
+type VariantStats struct {
+	Variant
+	Hits int `json:"hits"`
+}
+
+// Query conflict rules decide which value is used when a query parameter exists in both the destination and the request.
+const (
+	// QueryConflictLink keeps the values of the destination, it is the default rule.
+	QueryConflictLink = "link"
+	// QueryConflictRequest replaces the values of the destination with the values of the request.
+	QueryConflictRequest = "request"
+	// QueryConflictAppend keeps the values of the destination and appends the values of the request.
+	QueryConflictAppend = "append"
+)
+
+// Passthrough defines which parts of the request are passed to the destination on redirect.
+type Passthrough struct {
+	// Path appends the path after the hash, e.g. /abc1234/extra/path, to the path of the destination.
+	Path bool
+	// Query merges the query parameters of the request into the query of the destination.
+	Query bool
+	// QueryConflict is one of the query conflict rules, empty means QueryConflictLink.
+	QueryConflict string
+}
+
+// UserAgentOther is the value of the user agent fields which can not be recognized.
+const UserAgentOther = "other"
+
+// Operating systems of the user agents.
+const (
+	OSIOS      = "ios"
+	OSAndroid  = "android"
+	OSWindows  = "windows"
+	OSMacOS    = "macos"
+	OSLinux    = "linux"
+	OSChromeOS = "chromeos"
+)
+
+// Device classes of the user agents.
+const (
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceDesktop = "desktop"
+)
+
+// Browsers of the user agents.
+const (
+	BrowserChrome  = "chrome"
+	BrowserSafari  = "safari"
+	BrowserFirefox = "firefox"
+	BrowserEdge    = "edge"
+	BrowserOpera   = "opera"
+	BrowserSamsung = "samsung"
+)
+
+// UserAgent is the parsed User-Agent header of a request.
+type UserAgent struct {
+	OS      string
+	Device  string
+	Browser string
+}
+
+// DeviceRule redirects the user agents which match all of its non-empty fields to its URL.
+type DeviceRule struct {
+	OS      string `json:"os,omitempty"`
+	Device  string `json:"device,omitempty"`
+	Browser string `json:"browser,omitempty"`
+	URL     string `json:"url"`
+}
+
+// Matches reports whether the given user agent matches the rule.
+func (r DeviceRule) Matches(agent UserAgent) bool {
+	return (r.OS == "" || r.OS == agent.OS) &&
+		(r.Device == "" || r.Device == agent.Device) &&
+		(r.Browser == "" || r.Browser == agent.Browser)
+}
+
+// CountryRule redirects the visitors from the country with the ISO 3166-1 alpha-2 Country code to its URL.
+type CountryRule struct {
+	Country string `json:"country"`
+	URL     string `json:"url"`
+}
+
+// Metadata is the descriptive information of a short link which helps its owners to find and manage it.
+type Metadata struct {
+	Title string
+	Tags  []string
+	Notes string
+}
+
+// LinkUpdate is a change of an existing short link, the empty URL and the nil fields are left unchanged.
+type LinkUpdate struct {
+	URL   string
+	Actor string
+	Title *string
+	Tags  *[]string
+	Notes *string
+}
+
+// ExpandRequest is a request to expand the short URL with the Hash on the short URL domain with the Host.
+type ExpandRequest struct {
+	Host string
+	Hash string
+	// PathSuffix is the path after the hash without the leading slash.
+	PathSuffix string
+	Query      url.Values
+	// Referrer is the value of the Referer header of the request.
+	Referrer  string
+	UserAgent UserAgent
+	// RawUserAgent is the value of the User-Agent header of the request.
+	RawUserAgent string
+	// ClientIP is the IP of the visitor, empty when it is unknown.
+	ClientIP string
+	// Country is the ISO 3166-1 alpha-2 code of the country of the visitor, empty when it is unknown.
+	Country string
+	// Confirmed is set when the visitor has confirmed the destination on the interstitial page.
+	Confirmed bool
+	// Variant is the variant the visitor has been assigned to before, empty for the new visitors.
+	Variant string
+	// Bot is set when the request is made by a crawler, a link unfurler or an uptime checker.
+	Bot bool
+}
+
+// ListFilter filters the listed short links, the empty fields match every link.
+type ListFilter struct {
+	Workspace string
+	Tag       string
 }
 
 type ListData struct {
+	Hash         string            `json:"hash"`
+	Domain       string            `json:"domain,omitempty"`
+	OriginalURL  string            `json:"original_url"`
+	Hits         int               `json:"hits"`
+	HumanHits    int               `json:"human_hits"`
+	BotHits      int               `json:"bot_hits"`
+	Visitors     int               `json:"visitors,omitempty"`
+	MaxHits      int               `json:"max_hits,omitempty"`
+	NotBefore    *time.Time        `json:"not_before,omitempty"`
+	NotAfter     *time.Time        `json:"not_after,omitempty"`
+	Protected    bool              `json:"password_protected,omitempty"`
+	Title        string            `json:"title,omitempty"`
+	Tags         []string          `json:"tags,omitempty"`
+	Notes        string            `json:"notes,omitempty"`
+	Params       map[string]string `json:"params,omitempty"`
+	DeviceRules  []DeviceRule      `json:"device_rules,omitempty"`
+	CountryRules []CountryRule     `json:"country_rules,omitempty"`
+	Variants     []VariantStats    `json:"variants,omitempty"`
+	RedirectMode string            `json:"redirect_mode,omitempty"`
+	Interstitial bool              `json:"interstitial,omitempty"`
+	CreatedAt    *time.Time        `json:"created_at,omitempty"`
+}
+
+// HitEvent is a hit of a short link which is streamed to the event subscribers.
+type HitEvent struct {
+	Hash      string    `json:"hash"`
+	Domain    string    `json:"domain,omitempty"`
+	Workspace string    `json:"-"`
+	Time      time.Time `json:"time"`
+	// Referrer is the lowercase host of the referrer, empty when the visitor has no referrer.
+	Referrer string `json:"referrer,omitempty"`
+	Country  string `json:"country,omitempty"`
+	// Dropped is the number of the events which are dropped for the subscriber since its previous event.
+	Dropped int `json:"dropped,omitempty"`
+}
+
+// EventFilter selects the events of a subscriber, the empty Hashes match every hash in the workspace.
+type EventFilter struct {
+	Workspace string
+	Hashes    []string
+}
+
+// TopLink is a link with its hits in the requested window of the top links.
+type TopLink struct {
 	Hash        string `json:"hash"`
+	Domain      string `json:"domain,omitempty"`
 	OriginalURL string `json:"original_url"`
+	Title       string `json:"title,omitempty"`
 	Hits        int    `json:"hits"`
 }
@@ -0,0 +1,179 @@
+package model
+
+import (
+	"dh-url-shortener/internal/platform/hyperloglog"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// The granularities of the hit series.
+const (
+	GranularityMinute = "minute"
+	GranularityHour   = "hour"
+	GranularityDay    = "day"
+)
+
+// The retentions of the granularities, the buckets which are older than the retention of their granularity are dropped.
+// The day buckets are kept forever.
+const (
+	minuteRetention = 24 * time.Hour
+	hourRetention   = 30 * 24 * time.Hour
+)
+
+// GranularityDuration returns the size of the buckets of the given granularity, and false if the granularity is unknown.
+func GranularityDuration(granularity string) (time.Duration, bool) {
+	switch granularity {
+	case GranularityMinute:
+		return time.Minute, true
+	case GranularityHour:
+		return time.Hour, true
+	case GranularityDay:
+		return 24 * time.Hour, true
+	}
+	return 0, false
+}
+
+// HitSeries are the hits of a link in the time buckets of each granularity, keyed by the Unix time the bucket starts at.
+// Only the buckets with hits are stored, so the series of a link which is rarely hit stays small. The visitors of the
+// buckets are the HyperLogLog sketches of the fingerprints of their visitors.
+type HitSeries struct {
+	Minutes        map[int64]int    `json:",omitempty"`
+	Hours          map[int64]int    `json:",omitempty"`
+	Days           map[int64]int    `json:",omitempty"`
+	MinuteVisitors map[int64][]byte `json:",omitempty"`
+	HourVisitors   map[int64][]byte `json:",omitempty"`
+	DayVisitors    map[int64][]byte `json:",omitempty"`
+	mutex          sync.RWMutex
+}
+
+// Add adds a hit of the visitor with the given fingerprint at the given time, the hit is not added to the visitors
+// when the fingerprint is zero.
+func (s *HitSeries) Add(t time.Time, visitor uint64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.Minutes = addBucket(s.Minutes, t, time.Minute, minuteRetention)
+	s.Hours = addBucket(s.Hours, t, time.Hour, hourRetention)
+	s.Days = addBucket(s.Days, t, 24*time.Hour, 0)
+	if visitor != 0 {
+		s.MinuteVisitors = addVisitor(s.MinuteVisitors, t, time.Minute, minuteRetention, visitor)
+		s.HourVisitors = addVisitor(s.HourVisitors, t, time.Hour, hourRetention, visitor)
+		s.DayVisitors = addVisitor(s.DayVisitors, t, 24*time.Hour, 0, visitor)
+	}
+}
+
+// Range returns the hits and the visitor sketches of the buckets of the given granularity from the bucket which starts
+// at from up to the bucket which starts at to, including the buckets without hits. The sketches must not be changed.
+func (s *HitSeries) Range(granularity string, from, to time.Time) ([]int, [][]byte) {
+	size, ok := GranularityDuration(granularity)
+	if !ok || to.Before(from) {
+		return nil, nil
+	}
+	count := int(to.Sub(from)/size) + 1
+	hits, visitors := make([]int, count), make([][]byte, count)
+	if s == nil {
+		return hits, visitors
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	buckets, sketches := s.buckets(granularity)
+	for i := range hits {
+		start := from.Add(time.Duration(i) * size).Unix()
+		hits[i], visitors[i] = buckets[start], sketches[start]
+	}
+	return hits, visitors
+}
+
+// MarshalJSON marshals the buckets of the series under its lock.
+func (s *HitSeries) MarshalJSON() ([]byte, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return json.Marshal(struct {
+		Minutes        map[int64]int    `json:",omitempty"`
+		Hours          map[int64]int    `json:",omitempty"`
+		Days           map[int64]int    `json:",omitempty"`
+		MinuteVisitors map[int64][]byte `json:",omitempty"`
+		HourVisitors   map[int64][]byte `json:",omitempty"`
+		DayVisitors    map[int64][]byte `json:",omitempty"`
+	}{s.Minutes, s.Hours, s.Days, s.MinuteVisitors, s.HourVisitors, s.DayVisitors})
+}
+
+// buckets returns the hits and the visitor sketches of the buckets of the given granularity.
+func (s *HitSeries) buckets(granularity string) (map[int64]int, map[int64][]byte) {
+	switch granularity {
+	case GranularityMinute:
+		return s.Minutes, s.MinuteVisitors
+	case GranularityHour:
+		return s.Hours, s.HourVisitors
+	case GranularityDay:
+		return s.Days, s.DayVisitors
+	}
+	return nil, nil
+}
+
+// addBucket adds a hit to the bucket of the given size which contains the given time. The buckets older than the
+// retention are dropped when a new bucket is started, zero retention keeps all of them.
+func addBucket(buckets map[int64]int, t time.Time, size, retention time.Duration) map[int64]int {
+	if buckets == nil {
+		buckets = make(map[int64]int)
+	}
+	start := t.Truncate(size).Unix()
+	if _, ok := buckets[start]; !ok && retention > 0 {
+		oldest := t.Add(-retention).Truncate(size).Unix()
+		for s := range buckets {
+			if s < oldest {
+				delete(buckets, s)
+			}
+		}
+	}
+	buckets[start]++
+	return buckets
+}
+
+// addVisitor adds the given visitor to the sketch of the bucket of the given size which contains the given time.
+// The sketches older than the retention are dropped when a new bucket is started, zero retention keeps all of them.
+// Only the sketch of the bucket is copied, since the sketches are shared by the readers.
+func addVisitor(sketches map[int64][]byte, t time.Time, size, retention time.Duration, visitor uint64) map[int64][]byte {
+	if sketches == nil {
+		sketches = make(map[int64][]byte)
+	}
+	start := t.Truncate(size).Unix()
+	if _, ok := sketches[start]; !ok && retention > 0 {
+		oldest := t.Add(-retention).Truncate(size).Unix()
+		for s := range sketches {
+			if s < oldest {
+				delete(sketches, s)
+			}
+		}
+	}
+	sketches[start] = hyperloglog.Add(sketches[start], visitor)
+	return sketches
+}
+
+// StatsQuery is the range and the granularity of the requested stats, the zero values are replaced with their defaults.
+type StatsQuery struct {
+	From        time.Time
+	To          time.Time
+	Granularity string
+}
+
+// Stats are the hits and the estimated unique visitors of a link in the time buckets from From up to To,
+// and the breakdown of all hits of it.
+type Stats struct {
+	Hash        string        `json:"hash"`
+	Granularity string        `json:"granularity"`
+	From        time.Time     `json:"from"`
+	To          time.Time     `json:"to"`
+	Hits        int           `json:"hits"`
+	Visitors    int           `json:"visitors"`
+	Series      []StatsBucket `json:"series"`
+	Breakdown   Breakdown     `json:"breakdown"`
+}
+
+// StatsBucket is the hit count and the estimated unique visitors of a time bucket which starts at Time.
+type StatsBucket struct {
+	Time     time.Time `json:"time"`
+	Hits     int       `json:"hits"`
+	Visitors int       `json:"visitors"`
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"sync"
+	"time"
+)
+
+// AttemptLimiter limits the number of attempts per key within a fixed time window. An attempt is counted before it
+// is made, so the concurrent attempts can not exceed the limit, and the attempts of a key are reset when one succeeds.
+type AttemptLimiter struct {
+	MaxAttempts int
+	Window      time.Duration
+	attempts    map[string]attempts
+	// nextPrune is the time the expired windows are removed next.
+	nextPrune time.Time
+	mutex     sync.Mutex
+}
+
+type attempts struct {
+	count       int
+	windowStart time.Time
+}
+
+// NewAttemptLimiter creates a new attempt limiter which allows maxAttempts attempts per key within each window.
+func NewAttemptLimiter(maxAttempts int, window time.Duration) *AttemptLimiter {
+	return &AttemptLimiter{
+		MaxAttempts: maxAttempts,
+		Window:      window,
+		attempts:    make(map[string]attempts),
+	}
+}
+
+// Attempt counts an attempt for the given key at the given time, and reports whether it is allowed. The attempts which
+// are not allowed are not counted.
+func (l *AttemptLimiter) Attempt(key string, now time.Time) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	a, ok := l.attempts[key]
+	if !ok || now.Sub(a.windowStart) >= l.Window {
+		l.prune(now)
+		a = attempts{windowStart: now}
+	}
+	if a.count >= l.MaxAttempts {
+		return false
+	}
+	a.count++
+	l.attempts[key] = a
+	return true
+}
+
+// Reset forgets the attempts of the given key, it is called when an attempt succeeds.
+func (l *AttemptLimiter) Reset(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	delete(l.attempts, key)
+}
+
+// prune removes the keys whose windows are over at most once per window, so the keys which are not tried again
+// do not stay in memory.
+func (l *AttemptLimiter) prune(now time.Time) {
+	if now.Before(l.nextPrune) {
+		return
+	}
+	for key, a := range l.attempts {
+		if now.Sub(a.windowStart) >= l.Window {
+			delete(l.attempts, key)
+		}
+	}
+	l.nextPrune = now.Add(l.Window)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAttemptLimiter_ShouldBlockKeyAfterMaxAttempts(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(2, time.Minute)
+
+	assert.True(t, limiter.Attempt("05bf184", now))
+	assert.True(t, limiter.Attempt("05bf184", now))
+	assert.False(t, limiter.Attempt("05bf184", now))
+	assert.True(t, limiter.Attempt("8d505df", now))
+}
+
+func TestAttemptLimiter_ShouldAllowKeyAgainAfterWindow(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(1, time.Minute)
+
+	assert.True(t, limiter.Attempt("05bf184", now))
+	assert.False(t, limiter.Attempt("05bf184", now.Add(59*time.Second)))
+	assert.True(t, limiter.Attempt("05bf184", now.Add(time.Minute)))
+}
+
+func TestAttemptLimiter_ShouldAllowKeyAgainAfterReset(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(1, time.Minute)
+
+	assert.True(t, limiter.Attempt("05bf184", now))
+	limiter.Reset("05bf184")
+	assert.True(t, limiter.Attempt("05bf184", now))
+}
+
+func TestAttemptLimiter_ShouldNotExceedMaxAttemptsWhenAttemptedConcurrently(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(5, time.Minute)
+
+	var allowed int64
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if limiter.Attempt("05bf184", now) {
+				mutex.Lock()
+				allowed++
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int64(5), allowed)
+}
+
+func TestAttemptLimiter_ShouldPruneExpiredKeys(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	limiter := NewAttemptLimiter(1, time.Minute)
+	for i := 0; i < 10; i++ {
+		limiter.Attempt(fmt.Sprintf("key-%d", i), now)
+	}
+
+	limiter.Attempt("05bf184", now.Add(time.Minute))
+
+	assert.Len(t, limiter.attempts, 1)
+}
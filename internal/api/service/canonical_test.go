@@ -0,0 +1,101 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanonicalize(t *testing.T) {
+	tests := []struct {
+		name          string
+		url           string
+		stripTracking bool
+		expected      string
+	}{
+		{
+			name:     "lowercases scheme and host",
+			url:      "HTTPS://Example.COM/Path",
+			expected: "https://example.com/Path",
+		},
+		{
+			name:     "drops default http port",
+			url:      "http://example.com:80/a",
+			expected: "http://example.com/a",
+		},
+		{
+			name:     "drops default https port",
+			url:      "https://example.com:443/a",
+			expected: "https://example.com/a",
+		},
+		{
+			name:     "keeps non default port",
+			url:      "https://example.com:8443/a",
+			expected: "https://example.com:8443/a",
+		},
+		{
+			name:     "sorts query params and strips fragment",
+			url:      "https://example.com/a?b=1&a=2#x",
+			expected: "https://example.com/a?a=2&b=1",
+		},
+		{
+			name:     "adds root path",
+			url:      "https://example.com",
+			expected: "https://example.com/",
+		},
+		{
+			name:     "keeps tracking params by default",
+			url:      "https://example.com/a?utm_source=x&id=1",
+			expected: "https://example.com/a?id=1&utm_source=x",
+		},
+		{
+			name:          "strips tracking params",
+			url:           "https://example.com/a?utm_source=x&UTM_Medium=y&gclid=z&id=1",
+			stripTracking: true,
+			expected:      "https://example.com/a?id=1",
+		},
+		{
+			name:     "converts internationalized host to punycode",
+			url:      "https://Bücher.example/a",
+			expected: "https://xn--bcher-kva.example/a",
+		},
+		{
+			name:     "keeps ipv6 host",
+			url:      "http://[::1]:80/a",
+			expected: "http://[::1]/a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := Canonicalize(tt.url, tt.stripTracking)
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestCanonicalize_ShouldProduceSameFormForEquivalentURLs(t *testing.T) {
+	first, _ := Canonicalize("https://Example.com/a?b=1&a=2", false)
+	second, _ := Canonicalize("https://example.com/a?a=2&b=1#x", false)
+	assert.Equal(t, first, second)
+}
+
+func TestCanonicalize_ShouldReturnErrorWhenURLIsNotParseable(t *testing.T) {
+	_, err := Canonicalize("https://exa mple.com/%zz", false)
+	assert.Error(t, err)
+}
+
+func TestPunycodeEncode(t *testing.T) {
+	tests := map[string]string{
+		"münchen": "mnchen-3ya",
+		"bücher":  "bcher-kva",
+		"例え":      "r8jz45g",
+	}
+
+	for label, expected := range tests {
+		actual, err := punycodeEncode(label)
+		assert.Nil(t, err)
+		assert.Equal(t, expected, actual)
+	}
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	neturl "net/url"
+	"strings"
+)
+
+// findDomain returns the short URL domain whose host is the given host.
+// ShortURLDomain is the primary domain and it is returned when none of the ShortURLDomains has the given host.
+func (s Shortener) findDomain(host string) string {
+	host = strings.ToLower(host)
+	for _, domain := range s.ShortURLDomains {
+		if domainHost(domain) == host {
+			return domain
+		}
+	}
+	return s.ShortURLDomain
+}
+
+// linkKey returns the DB key of the link with the given hash on the given short URL domain.
+// Links of the primary domain are keyed by their hash only, so that the links created before
+// the multiple domain support keep working.
+func (s Shortener) linkKey(domain, hash string) string {
+	if domain == s.ShortURLDomain {
+		return hash
+	}
+	return domainHost(domain) + "/" + hash
+}
+
+// isKnownDomain reports whether the host is the host of the primary domain or one of the ShortURLDomains.
+func (s Shortener) isKnownDomain(host string) bool {
+	return s.findDomain(host) != s.ShortURLDomain || domainHost(s.ShortURLDomain) == strings.ToLower(host)
+}
+
+// domainHost returns the host of the short URL domain, or the domain itself if it is not a URL.
+func domainHost(domain string) string {
+	u, err := neturl.Parse(domain)
+	if err != nil || u.Host == "" {
+		return strings.ToLower(domain)
+	}
+	return strings.ToLower(u.Host)
+}
+
+// splitKey returns the domain host and the hash of the given DB key, the domain host of the primary domain links is empty.
+func splitKey(key string) (string, string) {
+	if i := strings.LastIndex(key, "/"); i >= 0 {
+		return key[:i], key[i+1:]
+	}
+	return "", key
+}
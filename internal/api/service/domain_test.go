@@ -0,0 +1,98 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"testing"
+	"time"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDomainShortener(db DB) Shortener {
+	return Shortener{DB: db, ShortURLDomain: "https://a.co", ShortURLDomains: []string{"https://b.co", "http://c.co:8080"}, Clock: func() time.Time { return createdAt }}
+}
+
+func TestShortener_Shorten_ShouldCreateShortURLOnChosenDomain(t *testing.T) {
+	tests := []struct {
+		domain           string
+		expectedKey      string
+		expectedShortURL string
+		expectedDomain   string
+	}{
+		{domain: "", expectedKey: "05bf184", expectedShortURL: "https://a.co/05bf184"},
+		{domain: "a.co", expectedKey: "05bf184", expectedShortURL: "https://a.co/05bf184"},
+		{domain: "B.co", expectedKey: "b.co/05bf184", expectedShortURL: "https://b.co/05bf184", expectedDomain: "b.co"},
+		{domain: "http://c.co:8080", expectedKey: "c.co:8080/05bf184", expectedShortURL: "http://c.co:8080/05bf184", expectedDomain: "c.co:8080"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.domain, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			mockDB := mocks.NewMockDB(controller)
+			expectedData := model.RedirectionData{OriginalURL: longURL, CanonicalURL: longURL, CreatedAt: createdAt, LinkOptions: model.LinkOptions{Domain: tt.expectedDomain}}
+			mockDB.EXPECT().Set(tt.expectedKey, expectedData).Return(nil).Times(1)
+
+			s := newDomainShortener(mockDB)
+			shortURL, err := s.Shorten(longURL, model.LinkOptions{Domain: tt.domain})
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expectedShortURL, shortURL)
+		})
+	}
+}
+
+func TestShortener_Shorten_ShouldReturnErrorWhenDomainIsUnknown(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).Times(0)
+
+	s := newDomainShortener(mockDB)
+	_, err := s.Shorten(longURL, model.LinkOptions{Domain: "d.co"})
+
+	assert.ErrorIs(t, err, model.ErrUnknownDomain)
+}
+
+func TestShortener_Expand_ShouldResolveDomainFromHost(t *testing.T) {
+	tests := map[string]string{
+		"a.co":           "05bf184",
+		"b.co":           "b.co/05bf184",
+		"B.CO":           "b.co/05bf184",
+		"c.co:8080":      "c.co:8080/05bf184",
+		"localhost:8080": "05bf184",
+	}
+
+	for host, expectedKey := range tests {
+		t.Run(host, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			mockDB := mocks.NewMockDB(controller)
+			mockDB.EXPECT().Get(expectedKey).Return(model.RedirectionData{OriginalURL: longURL}, nil).Times(1)
+			mockDB.EXPECT().Hit(expectedKey, gomock.Any()).Return(nil).Times(1)
+
+			s := newDomainShortener(mockDB)
+			url, err := s.Expand(model.ExpandRequest{Host: host, Hash: "05bf184"})
+
+			assert.Nil(t, err)
+			assert.Equal(t, longURL, url.URL)
+		})
+	}
+}
+
+func TestShortener_List_ShouldReturnHashAndDomainOfLinks(t *testing.T) {
+	data := map[string]model.RedirectionData{
+		"b.co/05bf184": {OriginalURL: longURL, LinkOptions: model.LinkOptions{Domain: "b.co"}},
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Data().Return(data).Times(1)
+
+	s := newDomainShortener(mockDB)
+	assert.Equal(t, []model.ListData{{Hash: "05bf184", Domain: "b.co", OriginalURL: longURL}}, s.List(model.ListFilter{}))
+}
@@ -0,0 +1,93 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"sync"
+	"sync/atomic"
+)
+
+// EventHub publishes the hit events to its subscribers. Publishing never blocks, an event is dropped for the subscribers
+// whose buffers are full, so the slow subscribers never slow the redirects down. The dropped events are reported
+// to the subscriber with its next event.
+type EventHub struct {
+	BufferSize  int
+	subscribers map[*subscriber]bool
+	mutex       sync.RWMutex
+}
+
+// subscriber is a subscription to the events which match its filter.
+type subscriber struct {
+	filter  model.EventFilter
+	events  chan model.HitEvent
+	dropped int64
+}
+
+// NewEventHub creates a new event hub which buffers up to bufferSize events for each subscriber.
+func NewEventHub(bufferSize int) *EventHub {
+	return &EventHub{BufferSize: bufferSize, subscribers: make(map[*subscriber]bool)}
+}
+
+// Subscribe returns the channel of the events which match the given filter, and the function which cancels the subscription
+// and closes the channel.
+func (h *EventHub) Subscribe(filter model.EventFilter) (<-chan model.HitEvent, func()) {
+	sub := &subscriber{filter: filter, events: make(chan model.HitEvent, h.BufferSize)}
+	h.mutex.Lock()
+	h.subscribers[sub] = true
+	h.mutex.Unlock()
+
+	var once sync.Once
+	return sub.events, func() {
+		once.Do(func() {
+			h.mutex.Lock()
+			delete(h.subscribers, sub)
+			h.mutex.Unlock()
+			close(sub.events)
+		})
+	}
+}
+
+// Publish sends the event to the subscribers whose filter matches it without waiting for them.
+func (h *EventHub) Publish(event model.HitEvent) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+	for sub := range h.subscribers {
+		if !sub.matches(event) {
+			continue
+		}
+		dropped := atomic.LoadInt64(&sub.dropped)
+		event.Dropped = int(dropped)
+		select {
+		case sub.events <- event:
+			atomic.AddInt64(&sub.dropped, -dropped)
+		default:
+			atomic.AddInt64(&sub.dropped, 1)
+		}
+	}
+}
+
+// matches reports whether the event is in the workspace of the subscriber and it has one of the hashes of the subscriber.
+func (s *subscriber) matches(event model.HitEvent) bool {
+	if event.Workspace != s.filter.Workspace {
+		return false
+	}
+	if len(s.filter.Hashes) == 0 {
+		return true
+	}
+	for _, hash := range s.filter.Hashes {
+		if hash == event.Hash {
+			return true
+		}
+	}
+	return false
+}
+
+// Subscribe returns the channel of the hit events which match the given filter, and the function which cancels the
+// subscription. The channel is closed immediately when the events are not published.
+func (s Shortener) Subscribe(filter model.EventFilter) (<-chan model.HitEvent, func()) {
+	if s.Events == nil {
+		events := make(chan model.HitEvent)
+		close(events)
+		return events, func() {}
+	}
+	return s.Events.Subscribe(filter)
+}
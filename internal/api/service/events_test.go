@@ -0,0 +1,79 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/db"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventHub_Publish_ShouldSendMatchingEvents(t *testing.T) {
+	hub := NewEventHub(10)
+	all, cancelAll := hub.Subscribe(model.EventFilter{})
+	defer cancelAll()
+	filtered, cancelFiltered := hub.Subscribe(model.EventFilter{Hashes: []string{"8d505df"}})
+	defer cancelFiltered()
+	other, cancelOther := hub.Subscribe(model.EventFilter{Workspace: "team-a"})
+	defer cancelOther()
+
+	hub.Publish(model.HitEvent{Hash: "05bf184"})
+	hub.Publish(model.HitEvent{Hash: "8d505df"})
+
+	assert.Equal(t, model.HitEvent{Hash: "05bf184"}, <-all)
+	assert.Equal(t, model.HitEvent{Hash: "8d505df"}, <-all)
+	assert.Equal(t, model.HitEvent{Hash: "8d505df"}, <-filtered)
+	assert.Empty(t, filtered)
+	assert.Empty(t, other)
+}
+
+func TestEventHub_Publish_ShouldDropEventsWhenSubscriberIsFull(t *testing.T) {
+	hub := NewEventHub(2)
+	events, cancel := hub.Subscribe(model.EventFilter{})
+	defer cancel()
+
+	for _, hash := range []string{"a", "b", "c", "d"} {
+		hub.Publish(model.HitEvent{Hash: hash})
+	}
+	assert.Equal(t, model.HitEvent{Hash: "a"}, <-events)
+	assert.Equal(t, model.HitEvent{Hash: "b"}, <-events)
+
+	hub.Publish(model.HitEvent{Hash: "e"})
+	assert.Equal(t, model.HitEvent{Hash: "e", Dropped: 2}, <-events)
+	hub.Publish(model.HitEvent{Hash: "f"})
+	assert.Equal(t, model.HitEvent{Hash: "f"}, <-events)
+}
+
+func TestEventHub_Subscribe_ShouldCloseEventsWhenCanceled(t *testing.T) {
+	hub := NewEventHub(2)
+	events, cancel := hub.Subscribe(model.EventFilter{})
+
+	cancel()
+	cancel()
+	hub.Publish(model.HitEvent{Hash: "a"})
+
+	_, ok := <-events
+	assert.False(t, ok)
+	assert.Empty(t, hub.subscribers)
+}
+
+func TestShortener_Expand_ShouldPublishHitEvent(t *testing.T) {
+	s := Shortener{DB: db.NewInMemoryDB(), ShortURLDomain: "http://localhost:8080", Events: NewEventHub(10), Clock: func() time.Time { return statsNow }}
+	_, _ = s.Shorten("https://www.yemeksepeti.com/istanbul", model.LinkOptions{Workspace: "team-a"})
+	events, cancel := s.Subscribe(model.EventFilter{Workspace: "team-a"})
+	defer cancel()
+
+	_, err := s.Expand(model.ExpandRequest{Hash: "05bf184", Referrer: "https://www.Google.com/search", Country: "TR"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.HitEvent{Hash: "05bf184", Workspace: "team-a", Time: statsNow, Referrer: "www.google.com", Country: "TR"}, <-events)
+}
+
+func TestShortener_Subscribe_ShouldCloseEventsWhenHubIsNil(t *testing.T) {
+	events, cancel := Shortener{}.Subscribe(model.EventFilter{})
+	defer cancel()
+
+	_, ok := <-events
+	assert.False(t, ok)
+}
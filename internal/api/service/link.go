@@ -0,0 +1,140 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"fmt"
+	"strings"
+)
+
+// Update changes the destination and the metadata of the short URL with the given hash on the given domain host
+// in the given workspace. Destination changes are recorded as new revisions.
+func (s Shortener) Update(workspace, host, hash string, update model.LinkUpdate) error {
+	var canonicalURL string
+	if update.URL != "" {
+		var err error
+		if canonicalURL, err = Canonicalize(update.URL, s.StripTrackingParams); err != nil {
+			return err
+		}
+		if err = s.checkPolicy(canonicalURL); err != nil {
+			return err
+		}
+	}
+
+	return s.DB.Update(s.linkKey(s.findDomain(host), hash), func(data *model.RedirectionData) error {
+		if data.Workspace != workspace {
+			return workspaceLinkNotFound(hash)
+		}
+		if update.URL != "" {
+			s.changeDestination(data, update.URL, canonicalURL, update.Actor)
+		}
+		if update.Title != nil {
+			data.Title = *update.Title
+		}
+		if update.Tags != nil {
+			data.Tags = normalizeTags(*update.Tags)
+		}
+		if update.Notes != nil {
+			data.Notes = *update.Notes
+		}
+		return nil
+	})
+}
+
+// History returns the revisions of the short URL with the given hash on the given domain host in the given workspace
+// in the order they are made
+func (s Shortener) History(workspace, host, hash string) ([]model.Revision, error) {
+	data, err := s.DB.Get(s.linkKey(s.findDomain(host), hash))
+	if err != nil {
+		return nil, err
+	}
+	if data.Workspace != workspace {
+		return nil, workspaceLinkNotFound(hash)
+	}
+
+	history := make([]model.Revision, len(data.Revisions))
+	copy(history, data.Revisions)
+	return history, nil
+}
+
+// RestoreRevision changes the destination of the short URL with the given hash on the given domain host in the given
+// workspace back to the destination it had at the given revision. Revision zero is the destination the short URL is
+// created with. Restoring is recorded as a new revision as well.
+func (s Shortener) RestoreRevision(workspace, host, hash string, revision int, actor string) error {
+	return s.DB.Update(s.linkKey(s.findDomain(host), hash), func(data *model.RedirectionData) error {
+		if data.Workspace != workspace {
+			return workspaceLinkNotFound(hash)
+		}
+		if revision < 0 || revision > len(data.Revisions) {
+			return model.ErrRevisionNotFound
+		}
+
+		url := data.OriginalURL
+		if revision == 0 && len(data.Revisions) > 0 {
+			url = data.Revisions[0].OldURL
+		} else if revision > 0 {
+			url = data.Revisions[revision-1].NewURL
+		}
+
+		canonicalURL, err := Canonicalize(url, s.StripTrackingParams)
+		if err != nil {
+			return err
+		}
+		if err = s.checkPolicy(canonicalURL); err != nil {
+			return err
+		}
+
+		s.changeDestination(data, url, canonicalURL, actor)
+		return nil
+	})
+}
+
+// workspaceLinkNotFound is returned for the links of the other workspaces, so that their existence is not revealed.
+func workspaceLinkNotFound(hash string) error {
+	return fmt.Errorf("%s %w", hash, model.ErrLinkNotFound)
+}
+
+// changeDestination sets the destination of the data and appends the change to its revisions.
+func (s Shortener) changeDestination(data *model.RedirectionData, url, canonicalURL, actor string) {
+	data.Revisions = append(data.Revisions, model.Revision{
+		Revision: len(data.Revisions) + 1,
+		Time:     s.now().UTC(),
+		OldURL:   data.OriginalURL,
+		NewURL:   url,
+		Actor:    actor,
+	})
+	data.OriginalURL = url
+	data.CanonicalURL = canonicalURL
+}
+
+// normalizeTags lowercases and trims the tags, and drops the empty and the duplicate ones.
+func normalizeTags(tags []string) []string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// hasTag reports whether the tags contain the given tag, the empty tag is contained by every tags.
+func hasTag(tags []string, tag string) bool {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return true
+	}
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
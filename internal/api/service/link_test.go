@@ -0,0 +1,160 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"errors"
+	"testing"
+	"time"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const newURL = "https://www.yemeksepeti.com/ankara"
+
+var revisionTime = time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+
+// updateWith makes the mock DB apply the update functions to the given data
+func updateWith(data *model.RedirectionData) func(string, func(*model.RedirectionData) error) error {
+	return func(_ string, update func(*model.RedirectionData) error) error {
+		updated := *data
+		if err := update(&updated); err != nil {
+			return err
+		}
+		*data = updated
+		return nil
+	}
+}
+
+func TestShortener_Update_ShouldChangeDestinationAndRecordRevision(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	data := model.RedirectionData{OriginalURL: longURL, CanonicalURL: longURL}
+	mockDB.EXPECT().Update("05bf184", gomock.Any()).DoAndReturn(updateWith(&data)).Times(1)
+
+	s := Shortener{DB: mockDB, Clock: func() time.Time { return revisionTime }}
+	err := s.Update("", "", "05bf184", model.LinkUpdate{URL: newURL, Actor: "tuncay"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, newURL, data.OriginalURL)
+	assert.Equal(t, newURL, data.CanonicalURL)
+	assert.Equal(t, []model.Revision{{Revision: 1, Time: revisionTime, OldURL: longURL, NewURL: newURL, Actor: "tuncay"}}, data.Revisions)
+}
+
+func TestShortener_Update_ShouldReturnErrorWhenPolicyRejectsDestination(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Update(gomock.Any(), gomock.Any()).Times(0)
+	mockPolicy := mocks.NewMockPolicy(controller)
+	mockPolicy.EXPECT().Check("evil.com").Return(&model.PolicyViolationError{Host: "evil.com", Rule: "block evil.com"}).Times(1)
+
+	s := Shortener{DB: mockDB, Policy: mockPolicy}
+	err := s.Update("", "", "05bf184", model.LinkUpdate{URL: "https://evil.com", Actor: "tuncay"})
+
+	var policyErr *model.PolicyViolationError
+	assert.True(t, errors.As(err, &policyErr))
+}
+
+func TestShortener_Update_ShouldReturnNotFoundWhenLinkIsInAnotherWorkspace(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	data := model.RedirectionData{OriginalURL: longURL, CanonicalURL: longURL, LinkOptions: model.LinkOptions{Workspace: "team-a"}}
+	mockDB.EXPECT().Update("05bf184", gomock.Any()).DoAndReturn(updateWith(&data)).Times(1)
+
+	s := Shortener{DB: mockDB}
+	err := s.Update("team-b", "", "05bf184", model.LinkUpdate{URL: newURL, Actor: "tuncay"})
+
+	assert.ErrorIs(t, err, model.ErrLinkNotFound)
+	assert.Equal(t, longURL, data.OriginalURL)
+	assert.Empty(t, data.Revisions)
+}
+
+func TestShortener_Update_ShouldChangeOnlyGivenMetadata(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	data := model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{Metadata: model.Metadata{Title: "title", Tags: []string{"food"}, Notes: "notes"}}}
+	mockDB.EXPECT().Update("05bf184", gomock.Any()).DoAndReturn(updateWith(&data)).Times(1)
+
+	title := "new title"
+	tags := []string{"Campaign", "campaign", " "}
+	s := Shortener{DB: mockDB}
+	err := s.Update("", "", "05bf184", model.LinkUpdate{Title: &title, Tags: &tags})
+
+	assert.Nil(t, err)
+	assert.Equal(t, longURL, data.OriginalURL)
+	assert.Empty(t, data.Revisions)
+	assert.Equal(t, model.Metadata{Title: "new title", Tags: []string{"campaign"}, Notes: "notes"}, data.Metadata)
+}
+
+func TestShortener_History(t *testing.T) {
+	revisions := []model.Revision{{Revision: 1, Time: revisionTime, OldURL: longURL, NewURL: newURL, Actor: "tuncay"}}
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: newURL, Revisions: revisions}, nil).Times(1)
+
+	s := Shortener{DB: mockDB}
+	history, err := s.History("", "", "05bf184")
+
+	assert.Nil(t, err)
+	assert.Equal(t, revisions, history)
+}
+
+func TestShortener_History_ShouldReturnEmptyHistoryWhenLinkIsNotUpdated(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL}, nil).Times(1)
+
+	s := Shortener{DB: mockDB}
+	history, err := s.History("", "", "05bf184")
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.Revision{}, history)
+}
+
+func TestShortener_RestoreRevision(t *testing.T) {
+	const thirdURL = "https://www.yemeksepeti.com/izmir"
+	tests := []struct {
+		name        string
+		revision    int
+		expectedURL string
+		wantErr     error
+	}{
+		{name: "restores the creation destination", revision: 0, expectedURL: longURL},
+		{name: "restores an intermediate revision", revision: 1, expectedURL: newURL},
+		{name: "restores the latest revision", revision: 2, expectedURL: thirdURL},
+		{name: "returns error when revision not exists", revision: 3, wantErr: model.ErrRevisionNotFound},
+		{name: "returns error when revision is negative", revision: -1, wantErr: model.ErrRevisionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			mockDB := mocks.NewMockDB(controller)
+			data := model.RedirectionData{OriginalURL: thirdURL, Revisions: []model.Revision{
+				{Revision: 1, OldURL: longURL, NewURL: newURL},
+				{Revision: 2, OldURL: newURL, NewURL: thirdURL},
+			}}
+			mockDB.EXPECT().Update("05bf184", gomock.Any()).DoAndReturn(updateWith(&data)).Times(1)
+
+			s := Shortener{DB: mockDB, Clock: func() time.Time { return revisionTime }}
+			err := s.RestoreRevision("", "", "05bf184", tt.revision, "tuncay")
+
+			assert.Equal(t, tt.wantErr, err)
+			if tt.wantErr != nil {
+				assert.Len(t, data.Revisions, 2)
+				return
+			}
+			assert.Equal(t, tt.expectedURL, data.OriginalURL)
+			assert.Equal(t, model.Revision{Revision: 3, Time: revisionTime, OldURL: thirdURL, NewURL: tt.expectedURL, Actor: "tuncay"}, data.Revisions[2])
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	neturl "net/url"
+	"strings"
+)
+
+// applyPassthrough passes the path suffix and the query of the request to the destination as the passthrough options allow.
+func applyPassthrough(destination string, passthrough model.Passthrough, req model.ExpandRequest) (string, error) {
+	if !passthrough.Path && (!passthrough.Query || len(req.Query) == 0) {
+		return destination, nil
+	}
+
+	u, err := neturl.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+
+	if passthrough.Path && req.PathSuffix != "" {
+		u.Path = strings.TrimSuffix(u.Path, "/") + "/" + req.PathSuffix
+		u.RawPath = ""
+	}
+
+	if passthrough.Query && len(req.Query) > 0 {
+		query := u.Query()
+		for key, values := range req.Query {
+			switch passthrough.QueryConflict {
+			case model.QueryConflictRequest:
+				query[key] = values
+			case model.QueryConflictAppend:
+				query[key] = append(query[key], values...)
+			default:
+				if _, ok := query[key]; !ok {
+					query[key] = values
+				}
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+
+	return u.String(), nil
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"net/url"
+	"testing"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyPassthrough(t *testing.T) {
+	const destination = "https://example.com/docs/?lang=en&v=1"
+	tests := []struct {
+		name        string
+		passthrough model.Passthrough
+		pathSuffix  string
+		query       url.Values
+		expected    string
+	}{
+		{
+			name:       "ignores path suffix and query when passthrough is disabled",
+			pathSuffix: "extra/path",
+			query:      url.Values{"x": {"1"}},
+			expected:   destination,
+		},
+		{
+			name:        "appends path suffix",
+			passthrough: model.Passthrough{Path: true},
+			pathSuffix:  "extra/path",
+			query:       url.Values{"x": {"1"}},
+			expected:    "https://example.com/docs/extra/path?lang=en&v=1",
+		},
+		{
+			name:        "merges query with link values winning by default",
+			passthrough: model.Passthrough{Query: true},
+			query:       url.Values{"x": {"1"}, "lang": {"tr"}},
+			expected:    "https://example.com/docs/?lang=en&v=1&x=1",
+		},
+		{
+			name:        "merges query with request values winning",
+			passthrough: model.Passthrough{Query: true, QueryConflict: model.QueryConflictRequest},
+			query:       url.Values{"x": {"1"}, "lang": {"tr"}},
+			expected:    "https://example.com/docs/?lang=tr&v=1&x=1",
+		},
+		{
+			name:        "merges query by appending request values",
+			passthrough: model.Passthrough{Query: true, QueryConflict: model.QueryConflictAppend},
+			query:       url.Values{"lang": {"tr"}},
+			expected:    "https://example.com/docs/?lang=en&lang=tr&v=1",
+		},
+		{
+			name:        "keeps destination when there is nothing to pass",
+			passthrough: model.Passthrough{Path: true, Query: true},
+			expected:    "https://example.com/docs/?lang=en&v=1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := applyPassthrough(destination, tt.passthrough, model.ExpandRequest{PathSuffix: tt.pathSuffix, Query: tt.query})
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, actual)
+		})
+	}
+}
+
+func TestShortener_Expand_ShouldReturnNotFoundWhenLinkDoesNotPassPathThrough(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL}, nil).Times(1)
+	mockDB.EXPECT().Hit(gomock.Any(), gomock.Any()).Times(0)
+
+	s := Shortener{DB: mockDB}
+	_, err := s.Expand(model.ExpandRequest{Hash: "05bf184", PathSuffix: "extra"})
+
+	assert.ErrorIs(t, err, model.ErrLinkNotFound)
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestPbkdf2SHA256 uses the PBKDF2-HMAC-SHA256 test vector of RFC 7914
+func TestPbkdf2SHA256(t *testing.T) {
+	key := pbkdf2SHA256([]byte("passwd"), []byte("salt"), 1, 64)
+	expected := "55ac046e56e3089fec1691c22544b605f94185216dde0465e68b9d57c20dacbc" +
+		"49ca9cccf179b645991664b39d77ef317c71b845b1e30bd509112041d3a19783"
+	assert.Equal(t, expected, hex.EncodeToString(key))
+}
+
+func TestHashPassword_ShouldVerifyOnlyTheSamePassword(t *testing.T) {
+	passwordHash, err := hashPassword("s3cret")
+	assert.Nil(t, err)
+	assert.NotContains(t, passwordHash, "s3cret")
+	assert.True(t, verifyPassword(passwordHash, "s3cret"))
+	assert.False(t, verifyPassword(passwordHash, "secret"))
+}
+
+func TestHashPassword_ShouldUseRandomSalt(t *testing.T) {
+	first, _ := hashPassword("s3cret")
+	second, _ := hashPassword("s3cret")
+	assert.NotEqual(t, first, second)
+}
+
+func TestVerifyPassword_ShouldReturnFalseWhenHashIsMalformed(t *testing.T) {
+	malformedHashes := []string{"", "s3cret", "md5$1$c2FsdA$a2V5", "pbkdf2-sha256$x$c2FsdA$a2V5", "pbkdf2-sha256$1$!$a2V5"}
+	for _, passwordHash := range malformedHashes {
+		assert.False(t, verifyPassword(passwordHash, "s3cret"), passwordHash)
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+)
+
+// Preview returns the information about the short URL with the given hash on the short URL domain with the given host
+// without counting a hit. The destination of a link outside of its activation window, of a used up link or of a password
+// protected link is not revealed, the same error as for the redirection is returned instead.
+func (s Shortener) Preview(host, hash string) (model.Preview, error) {
+	redirectionData, err := s.DB.Get(s.linkKey(s.findDomain(host), hash))
+	if err != nil {
+		return model.Preview{}, err
+	}
+
+	if err = s.checkPolicy(redirectionData.OriginalURL); err != nil {
+		return model.Preview{}, err
+	}
+
+	if err = s.checkActivationWindow(redirectionData.LinkOptions); err != nil {
+		return model.Preview{}, err
+	}
+
+	if err = checkHitLimit(redirectionData); err != nil {
+		return model.Preview{}, err
+	}
+
+	if redirectionData.Password != "" {
+		return model.Preview{}, model.ErrPasswordRequired
+	}
+
+	return model.Preview{
+		Hash:      hash,
+		URL:       redirectionData.OriginalURL,
+		Title:     redirectionData.Title,
+		CreatedAt: redirectionData.CreatedAt,
+		Hits:      redirectionData.Hits,
+	}, nil
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"testing"
+	"time"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortener_Preview(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	data := model.RedirectionData{OriginalURL: longURL, Hits: 3, CreatedAt: createdAt, LinkOptions: model.LinkOptions{Metadata: model.Metadata{Title: "Istanbul"}}}
+	mockDB.EXPECT().Get("05bf184").Return(data, nil).Times(1)
+	mockDB.EXPECT().Hit(gomock.Any(), gomock.Any()).Times(0)
+
+	s := Shortener{DB: mockDB}
+	preview, err := s.Preview("", "05bf184")
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.Preview{Hash: "05bf184", URL: longURL, Title: "Istanbul", CreatedAt: createdAt, Hits: 3}, preview)
+}
+
+func TestShortener_Preview_ShouldReturnErrorWhenLinkIsPasswordProtected(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{Password: "hash"}}, nil).Times(1)
+
+	s := Shortener{DB: mockDB}
+	preview, err := s.Preview("", "05bf184")
+
+	assert.ErrorIs(t, err, model.ErrPasswordRequired)
+	assert.Equal(t, model.Preview{}, preview)
+}
+
+func TestShortener_Expand_ShouldNotCountHitUntilInterstitialIsConfirmed(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{Interstitial: true}}, nil).Times(2)
+	mockDB.EXPECT().Hit("05bf184", gomock.Any()).Return(nil).Times(1)
+
+	s := Shortener{DB: mockDB}
+	redirection, err := s.Expand(model.ExpandRequest{Hash: "05bf184"})
+	assert.Nil(t, err)
+	assert.Equal(t, model.Redirection{URL: longURL, Interstitial: true, Cacheable: true}, redirection)
+
+	redirection, err = s.Expand(model.ExpandRequest{Hash: "05bf184", Confirmed: true})
+	assert.Nil(t, err)
+	assert.Equal(t, model.Redirection{URL: longURL, Cacheable: true}, redirection)
+}
+
+func TestShortener_Preview_ShouldReturnErrorWhenLinkIsNotActiveYet(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	data := model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{NotBefore: createdAt.Add(time.Hour)}}
+	mockDB.EXPECT().Get("05bf184").Return(data, nil).Times(1)
+
+	s := Shortener{DB: mockDB, Clock: func() time.Time { return createdAt }}
+	preview, err := s.Preview("", "05bf184")
+
+	assert.ErrorIs(t, err, model.ErrLinkNotActive)
+	assert.Equal(t, model.Preview{}, preview)
+}
+
+func TestShortener_Preview_ShouldReturnErrorWhenLinkIsExpired(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	data := model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{NotAfter: createdAt}}
+	mockDB.EXPECT().Get("05bf184").Return(data, nil).Times(1)
+
+	s := Shortener{DB: mockDB, Clock: func() time.Time { return createdAt }}
+	preview, err := s.Preview("", "05bf184")
+
+	assert.ErrorIs(t, err, model.ErrLinkExpired)
+	assert.Equal(t, model.Preview{}, preview)
+}
+
+func TestShortener_Preview_ShouldReturnErrorWhenLinkHasUsedUpItsHits(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	data := model.RedirectionData{OriginalURL: longURL, Hits: 1, LinkOptions: model.LinkOptions{MaxHits: 1}}
+	mockDB.EXPECT().Get("05bf184").Return(data, nil).Times(1)
+
+	s := Shortener{DB: mockDB}
+	preview, err := s.Preview("", "05bf184")
+
+	assert.ErrorIs(t, err, model.ErrHitLimitReached)
+	assert.Equal(t, model.Preview{}, preview)
+}
+
+func TestShortener_Expand_ShouldNotShowInterstitialWhenLinkHasUsedUpItsHits(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	data := model.RedirectionData{OriginalURL: longURL, Hits: 1, LinkOptions: model.LinkOptions{Interstitial: true, MaxHits: 1}}
+	mockDB.EXPECT().Get("05bf184").Return(data, nil).Times(1)
+	mockDB.EXPECT().Hit(gomock.Any(), gomock.Any()).Times(0)
+
+	s := Shortener{DB: mockDB}
+	redirection, err := s.Expand(model.ExpandRequest{Hash: "05bf184"})
+
+	assert.ErrorIs(t, err, model.ErrHitLimitReached)
+	assert.Equal(t, model.Redirection{}, redirection)
+}
+
+func TestShortener_Expand_ShouldNotShowInterstitialWhenLinkIsExpired(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	data := model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{Interstitial: true, NotAfter: createdAt}}
+	mockDB.EXPECT().Get("05bf184").Return(data, nil).Times(1)
+	mockDB.EXPECT().Hit(gomock.Any(), gomock.Any()).Times(0)
+
+	s := Shortener{DB: mockDB, Clock: func() time.Time { return createdAt }}
+	redirection, err := s.Expand(model.ExpandRequest{Hash: "05bf184"})
+
+	assert.ErrorIs(t, err, model.ErrLinkExpired)
+	assert.Equal(t, model.Redirection{}, redirection)
+}
@@ -3,80 +3,448 @@ package service
 import (
 	"crypto/sha256"
 	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/hyperloglog"
+	"dh-url-shortener/internal/platform/safehash"
 	"fmt"
+	neturl "net/url"
+	"reflect"
+	"strings"
+	"time"
 )
 
+// maxHashAttempts is the number of the candidate hashes which are tried for a link before giving up.
+const maxHashAttempts = 100
+
 type Shortener struct {
+	// ShortURLDomain is the primary short URL domain which is used when no other domain is chosen.
 	ShortURLDomain string
-	DB             DB
+	// ShortURLDomains are the additional short URL domains, a link on one domain is independent of the links on the others.
+	ShortURLDomains     []string
+	StripTrackingParams bool
+	// SafeHashes creates the hashes from the safe hash alphabet with a check character instead of hexadecimal.
+	SafeHashes bool
+	DB         DB
+	Policy     Policy
+	// Words rejects the generated hashes which contain an offensive word, the hashes are not filtered when it is nil.
+	Words WordFilter
+	// Clock returns the current time, time.Now is used when it is nil.
+	Clock func() time.Time
+	// Random returns a random number in [0, n) to assign the visitors to variants, rand.Intn is used when it is nil.
+	Random func(n int) int
+	// Visitors fingerprints the visitors to estimate the unique visitors, they are not estimated when it is nil.
+	Visitors *Fingerprinter
+	// TopLinks keeps the links with the most hits, the top links are not kept when it is nil.
+	TopLinks *TopLinks
+	// Events publishes the hits to the event subscribers, the hits are not published when it is nil.
+	Events *EventHub
+	// PasswordAttempts limits the failed password attempts per link, the attempts are unlimited when it is nil.
+	PasswordAttempts *AttemptLimiter
 }
 
 type DB interface {
 	Get(string) (model.RedirectionData, error)
 	Set(string, model.RedirectionData) error
-	Hit(string) error
+	Hit(string, model.Hit) error
+	Update(string, func(*model.RedirectionData) error) error
 	Data() map[string]model.RedirectionData
 	Restore(map[string]model.RedirectionData)
 }
 
-// Shorten creates a short URL from a long URL
-func (s Shortener) Shorten(url string) (string, error) {
+// Policy decides whether a destination host is allowed to be shortened and expanded.
+type Policy interface {
+	Check(host string) error
+}
+
+// WordFilter finds the offensive words in hashes.
+type WordFilter interface {
+	Contains(text string) bool
+}
+
+// Shorten creates a short URL from a long URL with the given link options
+func (s Shortener) Shorten(url string, options model.LinkOptions) (string, error) {
 	if url == "" {
 		return "", fmt.Errorf("long url cannot be empty")
 	}
 
-	hash := s.createShortURLHash(url, 0)
-	shortURL := s.createShortURL(hash)
+	canonicalURL, err := Canonicalize(url, s.StripTrackingParams)
+	if err != nil {
+		return "", err
+	}
+
+	if err = s.checkPolicy(canonicalURL); err != nil {
+		return "", err
+	}
+	if err = s.checkDestinations(&options); err != nil {
+		return "", err
+	}
+
+	domain := s.ShortURLDomain
+	if options.Domain != "" {
+		host := domainHost(options.Domain)
+		if !s.isKnownDomain(host) {
+			return "", model.ErrUnknownDomain
+		}
+		domain = s.findDomain(host)
+	}
+	options.Domain = ""
+	if domain != s.ShortURLDomain {
+		options.Domain = domainHost(domain)
+	}
+
+	options.Tags = normalizeTags(options.Tags)
+	if err = validateParams(options.Params); err != nil {
+		return "", err
+	}
+	if len(options.Params) == 0 {
+		options.Params = nil
+	}
+	if options.Password != "" {
+		if options.Password, err = hashPassword(options.Password); err != nil {
+			return "", err
+		}
+	}
+
+	data := model.RedirectionData{OriginalURL: url, CanonicalURL: canonicalURL, CreatedAt: s.now().UTC(), LinkOptions: options}
+	hash, err := s.createShortURLHash(domain, data, 0)
+	if err != nil {
+		return "", err
+	}
+	shortURL := s.createShortURL(domain, hash)
 	return shortURL, nil
 }
 
-// createShortURLHash creates a hash from a long URL.
+// createShortURLHash creates a hash from the canonical form of a long URL on the given short URL domain.
 // Hash creation process is based on the following:
-// 1. Create a SHA256 hash from the long URL with collision counter
-// 2. Pick first seven character of the hash as the short URL
-// 3. If the short URL is already taken, create a new hash with collision counter and repeat the process
+// 1. Create a SHA256 hash from the canonical URL with collision counter
+// 2. Pick first seven character of the hash as the short URL, or encode it to a safe hash if SafeHashes is set
+// 3. If the short URL is already taken by the same canonical URL with the same options, reuse it unless it is limited or expired
+// 4. If the short URL contains an offensive word, create a new hash with collision counter and repeat the process
+// 5. If the short URL is taken by another link, create a new hash with collision counter and repeat the process
+// 6. Give up with model.ErrHashesExhausted after maxHashAttempts candidates
 
-func (s Shortener) createShortURLHash(url string, collisionCounter int) string {
-	input := []byte(url)
+func (s Shortener) createShortURLHash(domain string, data model.RedirectionData, collisionCounter int) (string, error) {
+	if collisionCounter >= maxHashAttempts {
+		return "", model.ErrHashesExhausted
+	}
+	input := []byte(data.CanonicalURL)
 	counter := []byte(fmt.Sprintf("%d", collisionCounter))
 	input = append(input, counter...)
 
-	hash := fmt.Sprintf("%x", sha256.Sum256(input))
-	shortHash := hash[:7]
+	sum := sha256.Sum256(input)
+	shortHash := fmt.Sprintf("%x", sum)[:7]
+	if s.SafeHashes {
+		shortHash = safehash.Encode(sum[:])
+	}
+	if s.Words != nil && s.Words.Contains(shortHash) {
+		return s.createShortURLHash(domain, data, collisionCounter+1)
+	}
 
-	if err := s.DB.Set(shortHash, model.RedirectionData{OriginalURL: url, Hits: 0}); err != nil {
-		return s.createShortURLHash(url, collisionCounter+1)
+	key := s.linkKey(domain, shortHash)
+	if err := s.DB.Set(key, data); err != nil {
+		if existing, getErr := s.DB.Get(key); getErr == nil && s.isReusableLink(existing, data) {
+			return shortHash, nil
+		}
+		return s.createShortURLHash(domain, data, collisionCounter+1)
 	}
 
-	return shortHash
+	return shortHash, nil
+}
+
+// isReusableLink reports whether the existing link can be reused for the new link. The links with a hit limit or
+// a password are never reused, since each of them is meant for its own recipients, and the expired links are not
+// reused since they no longer redirect.
+func (s Shortener) isReusableLink(existing, data model.RedirectionData) bool {
+	if existing.MaxHits > 0 || existing.Password != "" {
+		return false
+	}
+	if !existing.NotAfter.IsZero() && !s.now().Before(existing.NotAfter) {
+		return false
+	}
+	return existing.CanonicalURL == data.CanonicalURL && reflect.DeepEqual(existing.LinkOptions, data.LinkOptions)
 }
 
 // createShortURL creates a short URL from short URL domain and hash
-func (s Shortener) createShortURL(hash string) string {
-	return fmt.Sprintf("%s/%s", s.ShortURLDomain, hash)
+func (s Shortener) createShortURL(domain, hash string) string {
+	return fmt.Sprintf("%s/%s", domain, hash)
+}
+
+// Expand expands a short URL to a long URL, the short URL domain is resolved from the request host
+func (s Shortener) Expand(req model.ExpandRequest) (model.Redirection, error) {
+	return s.expand(req, nil)
+}
+
+// Unlock expands a password protected short URL to a long URL if the given password is correct
+func (s Shortener) Unlock(req model.ExpandRequest, password string) (model.Redirection, error) {
+	return s.expand(req, &password)
 }
 
-// Expand expands a short URL to a long URL
-func (s Shortener) Expand(hash string) (string, error) {
-	redirectionData, err := s.DB.Get(hash)
+// expand expands a short URL to a long URL, password is nil when it is not given
+func (s Shortener) expand(req model.ExpandRequest, password *string) (model.Redirection, error) {
+	key := s.linkKey(s.findDomain(req.Host), req.Hash)
+	redirectionData, err := s.DB.Get(key)
 	if err != nil {
-		return "", err
+		return model.Redirection{}, err
+	}
+
+	if req.PathSuffix != "" && !redirectionData.Passthrough.Path {
+		return model.Redirection{}, fmt.Errorf("%s/%s %w", req.Hash, req.PathSuffix, model.ErrLinkNotFound)
+	}
+
+	redirection := s.selectDestination(redirectionData, req)
+	redirection.Mode = redirectionData.RedirectMode
+	redirection.Cacheable = isStatic(redirectionData)
+	if err = s.checkPolicy(redirection.URL); err != nil {
+		return model.Redirection{}, err
+	}
+
+	if err = s.checkActivationWindow(redirectionData.LinkOptions); err != nil {
+		return model.Redirection{}, err
+	}
+
+	if err = checkHitLimit(redirectionData); err != nil {
+		return model.Redirection{}, err
 	}
 
-	err = s.DB.Hit(hash)
+	if err = s.checkPassword(key, redirectionData.Password, password); err != nil {
+		return model.Redirection{}, err
+	}
+
+	redirection.URL, err = applyPassthrough(redirection.URL, redirectionData.Passthrough, req)
 	if err != nil {
-		return "", err
+		return model.Redirection{}, err
+	}
+
+	redirection.URL, err = applyParams(redirection.URL, redirectionData.Params, templateContext{req: req, now: s.now()})
+	if err != nil {
+		return model.Redirection{}, err
+	}
+
+	if redirectionData.Interstitial && !req.Confirmed {
+		redirection.Interstitial = true
+		return redirection, nil
 	}
 
-	return redirectionData.OriginalURL, nil
+	now := s.now().UTC()
+	err = s.DB.Hit(key, model.Hit{
+		Variant:      redirection.Variant,
+		Time:         now,
+		ReferrerHost: referrerHost(req.Referrer),
+		UserAgent:    req.UserAgent,
+		Visitor:      s.fingerprint(req, now),
+		Bot:          req.Bot,
+	})
+	if err != nil {
+		return model.Redirection{}, err
+	}
+	if s.TopLinks != nil {
+		s.TopLinks.Hit(redirectionData.Workspace, key, now)
+	}
+	if s.Events != nil {
+		s.Events.Publish(model.HitEvent{
+			Hash:      req.Hash,
+			Domain:    redirectionData.Domain,
+			Workspace: redirectionData.Workspace,
+			Time:      now,
+			Referrer:  referrerHost(req.Referrer),
+			Country:   req.Country,
+		})
+	}
+
+	return redirection, nil
+}
+
+// fingerprint returns the fingerprint of the visitor of the request, or zero if the visitors are not fingerprinted
+// or the IP of the visitor is unknown.
+func (s Shortener) fingerprint(req model.ExpandRequest, now time.Time) uint64 {
+	if s.Visitors == nil || req.ClientIP == "" {
+		return 0
+	}
+	return s.Visitors.Fingerprint(req.ClientIP, req.RawUserAgent, now)
+}
+
+// referrerHost returns the lowercase host of the referrer, or empty if the referrer is not a URL.
+func referrerHost(referrer string) string {
+	u, err := neturl.Parse(referrer)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// selectDestination returns the URL of the first device rule which matches the user agent of the request,
+// or the URL of the country rule of the country of the request. The other visitors are assigned to a variant
+// if the link has any, otherwise they are redirected to the original URL.
+func (s Shortener) selectDestination(data model.RedirectionData, req model.ExpandRequest) model.Redirection {
+	for _, rule := range data.DeviceRules {
+		if rule.Matches(req.UserAgent) {
+			return model.Redirection{URL: rule.URL}
+		}
+	}
+	for _, rule := range data.CountryRules {
+		if req.Country != "" && rule.Country == req.Country {
+			return model.Redirection{URL: rule.URL}
+		}
+	}
+	if len(data.Variants) > 0 {
+		variant := selectVariant(data.Variants, req.Variant, s.random)
+		return model.Redirection{URL: variant.URL, Variant: variant.Name}
+	}
+	return model.Redirection{URL: data.OriginalURL}
+}
+
+// checkDestinations canonicalizes the destinations of the rules and the variants of the link and checks them against
+// the policy like the original URL. The empty rules and variants are set to nil, so that the links without them are
+// reused regardless of how they are given.
+func (s Shortener) checkDestinations(options *model.LinkOptions) error {
+	for _, url := range destinationURLs(*options) {
+		canonicalURL, err := Canonicalize(url, s.StripTrackingParams)
+		if err != nil {
+			return err
+		}
+		if err = s.checkPolicy(canonicalURL); err != nil {
+			return err
+		}
+	}
+
+	if len(options.DeviceRules) == 0 {
+		options.DeviceRules = nil
+	}
+	if len(options.CountryRules) == 0 {
+		options.CountryRules = nil
+	}
+	if len(options.Variants) == 0 {
+		options.Variants = nil
+	}
+	return nil
+}
+
+// destinationURLs returns the URLs of the rules and the variants of the link, the original URL is not included.
+func destinationURLs(options model.LinkOptions) []string {
+	var urls []string
+	for _, rule := range options.DeviceRules {
+		urls = append(urls, rule.URL)
+	}
+	for _, rule := range options.CountryRules {
+		urls = append(urls, rule.URL)
+	}
+	for _, variant := range options.Variants {
+		urls = append(urls, variant.URL)
+	}
+	return urls
+}
+
+// isStatic reports whether the link redirects every visitor to the same destination and every hit may be skipped,
+// which is not the case for the links with rules, variants, params, a hit limit, an expiry or a password.
+func isStatic(data model.RedirectionData) bool {
+	return len(data.DeviceRules) == 0 && len(data.CountryRules) == 0 && len(data.Variants) == 0 && len(data.Params) == 0 &&
+		data.MaxHits == 0 && data.NotAfter.IsZero() && data.Password == ""
 }
 
-// List converts the data in the database to a list of data which contains short URL, long URL and hit count
-func (s Shortener) List() []model.ListData {
+// checkPolicy checks the destination host of the given URL against the policy if there is any.
+func (s Shortener) checkPolicy(url string) error {
+	if s.Policy == nil {
+		return nil
+	}
+
+	u, err := neturl.Parse(url)
+	if err != nil {
+		return err
+	}
+	return s.Policy.Check(u.Hostname())
+}
+
+// checkActivationWindow checks the current time is within the NotBefore and NotAfter times of the link.
+func (s Shortener) checkActivationWindow(options model.LinkOptions) error {
+	now := s.now()
+	if !options.NotBefore.IsZero() && now.Before(options.NotBefore) {
+		return model.ErrLinkNotActive
+	}
+	if !options.NotAfter.IsZero() && !now.Before(options.NotAfter) {
+		return model.ErrLinkExpired
+	}
+	return nil
+}
+
+// checkHitLimit checks the link has not used up its hits yet, so that a used up link is not revealed by the interstitial
+// or the preview. The limit is checked again atomically when the hit is counted.
+func checkHitLimit(data model.RedirectionData) error {
+	if data.MaxHits > 0 && data.Hits >= data.MaxHits {
+		return model.ErrHitLimitReached
+	}
+	return nil
+}
+
+// checkPassword checks the given password against the password hash of the link with the given key if the link is password protected.
+func (s Shortener) checkPassword(key, passwordHash string, password *string) error {
+	if passwordHash == "" {
+		return nil
+	}
+	if password == nil {
+		return model.ErrPasswordRequired
+	}
+
+	now := s.now()
+	if s.PasswordAttempts != nil && !s.PasswordAttempts.Attempt(key, now) {
+		return model.ErrTooManyAttempts
+	}
+	if !verifyPassword(passwordHash, *password) {
+		return model.ErrInvalidPassword
+	}
+	if s.PasswordAttempts != nil {
+		s.PasswordAttempts.Reset(key)
+	}
+	return nil
+}
+
+// now returns the current time of the clock.
+func (s Shortener) now() time.Time {
+	if s.Clock == nil {
+		return time.Now()
+	}
+	return s.Clock()
+}
+
+// List converts the data in the database which matches the filter to a list of data
+// which contains short URL, long URL, hit count and the link options
+func (s Shortener) List(filter model.ListFilter) []model.ListData {
 	data := s.DB.Data()
 	list := make([]model.ListData, 0, len(data))
 	for k, v := range data {
-		list = append(list, model.ListData{Hash: k, OriginalURL: v.OriginalURL, Hits: v.Hits})
+		if v.Workspace != filter.Workspace || !hasTag(v.Tags, filter.Tag) {
+			continue
+		}
+		_, hash := splitKey(k)
+		list = append(list, model.ListData{
+			Hash:         hash,
+			Domain:       v.Domain,
+			OriginalURL:  v.OriginalURL,
+			Hits:         v.Hits,
+			HumanHits:    v.Hits - v.BotHits,
+			BotHits:      v.BotHits,
+			Visitors:     hyperloglog.Estimate(v.Visitors),
+			MaxHits:      v.MaxHits,
+			NotBefore:    optionalTime(v.NotBefore),
+			NotAfter:     optionalTime(v.NotAfter),
+			Protected:    v.Password != "",
+			Title:        v.Title,
+			Tags:         v.Tags,
+			Notes:        v.Notes,
+			Params:       v.Params,
+			DeviceRules:  v.DeviceRules,
+			CountryRules: v.CountryRules,
+			Variants:     variantStats(v),
+			RedirectMode: v.RedirectMode,
+			Interstitial: v.Interstitial,
+			CreatedAt:    optionalTime(v.CreatedAt),
+		})
 	}
 	return list
 }
+
+// optionalTime returns nil for the zero time so that it is omitted from the responses.
+func optionalTime(t time.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t
+}
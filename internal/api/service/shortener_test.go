@@ -2,8 +2,12 @@ package service
 
 import (
 	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/db"
+	"dh-url-shortener/internal/platform/wordfilter"
 	"errors"
+	"strings"
 	"testing"
+	"time"
 
 	mocks "dh-url-shortener/.mocks"
 
@@ -13,10 +17,12 @@ import (
 
 const longURL = "https://www.yemeksepeti.com/istanbul"
 
+var createdAt = time.Date(2022, 6, 1, 9, 0, 0, 0, time.UTC)
+
 // TestShortener_Shorten should return error when url is empty
 func TestShortener_Shorten_ShouldReturnErrorWhenLongURLIsEmpty(t *testing.T) {
 	s := Shortener{}
-	shortURL, err := s.Shorten("")
+	shortURL, err := s.Shorten("", model.LinkOptions{})
 
 	assert.Error(t, err)
 	assert.Equal(t, "", shortURL)
@@ -31,7 +37,7 @@ func TestShortener_Shorten_ShouldReturnFirstSevenCharsOfMd5Hash(t *testing.T) {
 
 	s := Shortener{DB: mockDB}
 	longURL := "https://www.yemeksepeti.com/istanbul"
-	shortURL, err := s.Shorten(longURL)
+	shortURL, err := s.Shorten(longURL, model.LinkOptions{})
 	expected := "/05bf184"
 	assert.Nil(t, err)
 	assert.Equal(t, expected, shortURL)
@@ -46,7 +52,7 @@ func TestShortener_Shorten_ShouldReturnShortUrlWhenIfItIsNotUsedBefore(t *testin
 
 	s := Shortener{DB: mockDB}
 	longURL := "https://www.yemeksepeti.com/istanbul"
-	shortURL, err := s.Shorten(longURL)
+	shortURL, err := s.Shorten(longURL, model.LinkOptions{})
 	expected := "/05bf184"
 	assert.Nil(t, err)
 	assert.Equal(t, expected, shortURL)
@@ -58,17 +64,192 @@ func TestShortener_Shorten_ShouldReturnDifferentShortUrlWhenIfItIsUsedBefore(t *
 	defer controller.Finish()
 	mockDB := mocks.NewMockDB(controller)
 	gomock.InOrder(
-		mockDB.EXPECT().Set("05bf184", model.RedirectionData{OriginalURL: longURL}).Return(errors.New("hash already exists")).Times(1),
-		mockDB.EXPECT().Set("8d505df", model.RedirectionData{OriginalURL: longURL}).Return(nil).Times(1),
+		mockDB.EXPECT().Set("05bf184", model.RedirectionData{OriginalURL: longURL, CanonicalURL: longURL, CreatedAt: createdAt}).Return(errors.New("hash already exists")).Times(1),
+		mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: "https://example.com", CanonicalURL: "https://example.com/"}, nil).Times(1),
+		mockDB.EXPECT().Set("8d505df", model.RedirectionData{OriginalURL: longURL, CanonicalURL: longURL, CreatedAt: createdAt}).Return(nil).Times(1),
 	)
 
-	s := Shortener{DB: mockDB}
-	shortURL, err := s.Shorten(longURL)
+	s := Shortener{DB: mockDB, Clock: func() time.Time { return createdAt }}
+	shortURL, err := s.Shorten(longURL, model.LinkOptions{})
 	expected := "/8d505df"
 	assert.Nil(t, err)
 	assert.Equal(t, expected, shortURL)
 }
 
+// TestShortener_Shorten should skip the generated hash when it contains an offensive word
+func TestShortener_Shorten_ShouldReturnDifferentShortUrlWhenItContainsOffensiveWord(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set("05bf184", gomock.Any()).Times(0)
+	mockDB.EXPECT().Set("8d505df", model.RedirectionData{OriginalURL: longURL, CanonicalURL: longURL, CreatedAt: createdAt}).Return(nil).Times(1)
+
+	s := Shortener{DB: mockDB, Words: wordfilter.New("sbf"), Clock: func() time.Time { return createdAt }}
+	shortURL, err := s.Shorten(longURL, model.LinkOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/8d505df", shortURL)
+}
+
+// TestShortener_Shorten should give up when every candidate hash contains an offensive word
+func TestShortener_Shorten_ShouldReturnErrorWhenEveryHashContainsOffensiveWord(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).Times(0)
+	mockWords := mocks.NewMockWordFilter(controller)
+	mockWords.EXPECT().Contains(gomock.Any()).Return(true).Times(maxHashAttempts)
+
+	s := Shortener{DB: mockDB, Words: mockWords}
+	shortURL, err := s.Shorten(longURL, model.LinkOptions{})
+
+	assert.ErrorIs(t, err, model.ErrHashesExhausted)
+	assert.Empty(t, shortURL)
+}
+
+// TestShortener_Shorten should give up when every candidate hash is taken by another link
+func TestShortener_Shorten_ShouldReturnErrorWhenEveryHashIsTaken(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).Return(errors.New("hash already exists")).Times(maxHashAttempts)
+	mockDB.EXPECT().Get(gomock.Any()).Return(model.RedirectionData{OriginalURL: "https://www.yemeksepeti.com/ankara"}, nil).Times(maxHashAttempts)
+
+	s := Shortener{DB: mockDB}
+	_, err := s.Shorten(longURL, model.LinkOptions{})
+
+	assert.ErrorIs(t, err, model.ErrHashesExhausted)
+}
+
+// TestShortener_Shorten should not reuse the existing short url when it has a hit limit, so each recipient has own hits
+func TestShortener_Shorten_ShouldCreateNewShortUrlWhenExistingHasHitLimit(t *testing.T) {
+	s := Shortener{DB: db.NewInMemoryDB(), Clock: func() time.Time { return createdAt }}
+	first, _ := s.Shorten(longURL, model.LinkOptions{MaxHits: 1})
+	second, err := s.Shorten(longURL, model.LinkOptions{MaxHits: 1})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/05bf184", first)
+	assert.Equal(t, "/8d505df", second)
+	_, err = s.Expand(model.ExpandRequest{Hash: "05bf184"})
+	assert.Nil(t, err)
+	redirection, err := s.Expand(model.ExpandRequest{Hash: "8d505df"})
+	assert.Nil(t, err)
+	assert.Equal(t, longURL, redirection.URL)
+}
+
+// TestShortener_Shorten should not reuse the existing short url when it is password protected
+func TestShortener_Shorten_ShouldCreateNewShortUrlWhenExistingHasPassword(t *testing.T) {
+	s := Shortener{DB: db.NewInMemoryDB(), Clock: func() time.Time { return createdAt }}
+	first, _ := s.Shorten(longURL, model.LinkOptions{})
+	firstWithPassword, _ := s.Shorten(longURL, model.LinkOptions{Password: "s3cret"})
+	secondWithPassword, err := s.Shorten(longURL, model.LinkOptions{Password: "s3cret"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/05bf184", first)
+	assert.Equal(t, "/8d505df", firstWithPassword)
+	assert.NotEqual(t, firstWithPassword, secondWithPassword)
+}
+
+// TestShortener_Shorten should not reuse the existing short url when it is expired
+func TestShortener_Shorten_ShouldCreateNewShortUrlWhenExistingIsExpired(t *testing.T) {
+	now := createdAt
+	s := Shortener{DB: db.NewInMemoryDB(), Clock: func() time.Time { return now }}
+	options := model.LinkOptions{NotAfter: createdAt.Add(time.Hour)}
+	first, _ := s.Shorten(longURL, options)
+	reused, _ := s.Shorten(longURL, options)
+
+	now = createdAt.Add(2 * time.Hour)
+	second, err := s.Shorten(longURL, options)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/05bf184", first)
+	assert.Equal(t, first, reused)
+	assert.Equal(t, "/8d505df", second)
+}
+
+// TestShortener_Shorten should return the existing short url when the same canonical url is shortened before
+func TestShortener_Shorten_ShouldReturnExistingShortUrlWhenCanonicalURLIsUsedBefore(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	gomock.InOrder(
+		mockDB.EXPECT().Set("05bf184", gomock.Any()).Return(errors.New("hash already exists")).Times(1),
+		mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL, CanonicalURL: longURL}, nil).Times(1),
+	)
+
+	s := Shortener{DB: mockDB}
+	shortURL, err := s.Shorten("HTTPS://WWW.yemeksepeti.com:443/istanbul#menu", model.LinkOptions{})
+	assert.Nil(t, err)
+	assert.Equal(t, "/05bf184", shortURL)
+}
+
+// TestShortener_Shorten should store the original url and hash the canonical url
+func TestShortener_Shorten_ShouldStoreOriginalURLAndHashCanonicalURL(t *testing.T) {
+	originalURL := "https://Example.com/a?b=1&a=2"
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set(gomock.Any(), model.RedirectionData{OriginalURL: originalURL, CanonicalURL: "https://example.com/a?a=2&b=1", CreatedAt: createdAt}).Return(nil).Times(1)
+
+	s := Shortener{DB: mockDB, Clock: func() time.Time { return createdAt }}
+	first, err := s.Shorten(originalURL, model.LinkOptions{})
+	assert.Nil(t, err)
+
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	second, _ := s.Shorten("https://example.com/a?a=2&b=1#x", model.LinkOptions{})
+	assert.Equal(t, first, second)
+}
+
+// TestShortener_Shorten should not reuse an existing short url when the link options are different
+func TestShortener_Shorten_ShouldNotReuseShortUrlWhenLinkOptionsAreDifferent(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	options := model.LinkOptions{MaxHits: 1}
+	gomock.InOrder(
+		mockDB.EXPECT().Set("05bf184", gomock.Any()).Return(errors.New("hash already exists")).Times(1),
+		mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL, CanonicalURL: longURL}, nil).Times(1),
+		mockDB.EXPECT().Set("8d505df", model.RedirectionData{OriginalURL: longURL, CanonicalURL: longURL, CreatedAt: createdAt, LinkOptions: options}).Return(nil).Times(1),
+	)
+
+	s := Shortener{DB: mockDB, Clock: func() time.Time { return createdAt }}
+	shortURL, err := s.Shorten(longURL, options)
+	assert.Nil(t, err)
+	assert.Equal(t, "/8d505df", shortURL)
+}
+
+func TestShortener_Shorten_ShouldReturnErrorWhenPolicyRejectsDestination(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).Times(0)
+	mockPolicy := mocks.NewMockPolicy(controller)
+	mockPolicy.EXPECT().Check("www.yemeksepeti.com").Return(&model.PolicyViolationError{Host: "www.yemeksepeti.com", Rule: "block *.yemeksepeti.com"}).Times(1)
+
+	s := Shortener{DB: mockDB, Policy: mockPolicy}
+	shortURL, err := s.Shorten("https://WWW.yemeksepeti.com/istanbul", model.LinkOptions{})
+
+	var policyErr *model.PolicyViolationError
+	assert.True(t, errors.As(err, &policyErr))
+	assert.Equal(t, "", shortURL)
+}
+
+func TestShortener_Expand_ShouldReturnErrorWhenPolicyRejectsDestination(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get(gomock.Any()).Return(model.RedirectionData{OriginalURL: longURL}, nil).Times(1)
+	mockDB.EXPECT().Hit(gomock.Any(), gomock.Any()).Times(0)
+	mockPolicy := mocks.NewMockPolicy(controller)
+	mockPolicy.EXPECT().Check("www.yemeksepeti.com").Return(&model.PolicyViolationError{Host: "www.yemeksepeti.com", Rule: "not in allowlist"}).Times(1)
+
+	s := Shortener{DB: mockDB, Policy: mockPolicy}
+	url, err := s.Expand(model.ExpandRequest{Hash: "05bf184"})
+
+	assert.Error(t, err)
+	assert.Equal(t, "", url.URL)
+}
+
 func TestShortener_Expand_ShouldReturnErrorWhenHashNotFound(t *testing.T) {
 	controller := gomock.NewController(t)
 	defer controller.Finish()
@@ -77,10 +258,10 @@ func TestShortener_Expand_ShouldReturnErrorWhenHashNotFound(t *testing.T) {
 
 	s := Shortener{DB: mockDB}
 	hash := "05bf184"
-	originalURL, err := s.Expand(hash)
+	originalURL, err := s.Expand(model.ExpandRequest{Hash: hash})
 
 	assert.Error(t, err)
-	assert.Equal(t, "", originalURL)
+	assert.Equal(t, "", originalURL.URL)
 }
 
 func TestShortener_Expand_ShouldReturnLongURL(t *testing.T) {
@@ -88,14 +269,142 @@ func TestShortener_Expand_ShouldReturnLongURL(t *testing.T) {
 	defer controller.Finish()
 	mockDB := mocks.NewMockDB(controller)
 	mockDB.EXPECT().Get(gomock.Any()).Return(model.RedirectionData{OriginalURL: longURL}, nil).Times(1)
-	mockDB.EXPECT().Hit(gomock.Any()).Return(nil).Times(1)
+	mockDB.EXPECT().Hit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
 
 	s := Shortener{DB: mockDB}
 	hash := "05bf184"
-	url, err := s.Expand(hash)
+	url, err := s.Expand(model.ExpandRequest{Hash: hash})
 
 	assert.Nil(t, err)
-	assert.Equal(t, longURL, url)
+	assert.Equal(t, longURL, url.URL)
+}
+
+func TestShortener_Expand_ShouldRedirectByDeviceRules(t *testing.T) {
+	rules := []model.DeviceRule{
+		{OS: model.OSIOS, URL: "https://apps.apple.com/app/id1"},
+		{OS: model.OSAndroid, Device: model.DeviceMobile, URL: "https://play.google.com/store/apps/details?id=app"},
+	}
+	tests := []struct {
+		name     string
+		agent    model.UserAgent
+		expected string
+	}{
+		{name: "ios", agent: model.UserAgent{OS: model.OSIOS, Device: model.DeviceTablet}, expected: "https://apps.apple.com/app/id1"},
+		{name: "android phone", agent: model.UserAgent{OS: model.OSAndroid, Device: model.DeviceMobile}, expected: "https://play.google.com/store/apps/details?id=app"},
+		{name: "android tablet falls back", agent: model.UserAgent{OS: model.OSAndroid, Device: model.DeviceTablet}, expected: longURL},
+		{name: "desktop falls back", agent: model.UserAgent{OS: model.OSWindows, Device: model.DeviceDesktop}, expected: longURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			mockDB := mocks.NewMockDB(controller)
+			mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{DeviceRules: rules}}, nil).Times(1)
+			mockDB.EXPECT().Hit("05bf184", gomock.Any()).Return(nil).Times(1)
+
+			s := Shortener{DB: mockDB}
+			url, err := s.Expand(model.ExpandRequest{Hash: "05bf184", UserAgent: tt.agent})
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, url.URL)
+		})
+	}
+}
+
+func TestShortener_Expand_ShouldRedirectByCountryRules(t *testing.T) {
+	options := model.LinkOptions{
+		DeviceRules:  []model.DeviceRule{{OS: model.OSIOS, URL: "https://apps.apple.com/app/id1"}},
+		CountryRules: []model.CountryRule{{Country: "TR", URL: "https://www.yemeksepeti.com/tr"}},
+	}
+	tests := []struct {
+		name     string
+		req      model.ExpandRequest
+		expected string
+	}{
+		{name: "country rule", req: model.ExpandRequest{Hash: "05bf184", Country: "TR"}, expected: "https://www.yemeksepeti.com/tr"},
+		{name: "device rule wins", req: model.ExpandRequest{Hash: "05bf184", Country: "TR", UserAgent: model.UserAgent{OS: model.OSIOS}}, expected: "https://apps.apple.com/app/id1"},
+		{name: "other country falls back", req: model.ExpandRequest{Hash: "05bf184", Country: "DE"}, expected: longURL},
+		{name: "unknown country falls back", req: model.ExpandRequest{Hash: "05bf184"}, expected: longURL},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			mockDB := mocks.NewMockDB(controller)
+			mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: options}, nil).Times(1)
+			mockDB.EXPECT().Hit("05bf184", gomock.Any()).Return(nil).Times(1)
+
+			s := Shortener{DB: mockDB}
+			redirection, err := s.Expand(tt.req)
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, redirection.URL)
+		})
+	}
+}
+
+func TestShortener_Shorten_ShouldReturnErrorWhenPolicyRejectsDeviceRuleDestination(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).Times(0)
+	mockPolicy := mocks.NewMockPolicy(controller)
+	mockPolicy.EXPECT().Check("www.yemeksepeti.com").Return(nil).Times(1)
+	mockPolicy.EXPECT().Check("evil.com").Return(&model.PolicyViolationError{Host: "evil.com", Rule: "block evil.com"}).Times(1)
+
+	s := Shortener{DB: mockDB, Policy: mockPolicy}
+	_, err := s.Shorten(longURL, model.LinkOptions{DeviceRules: []model.DeviceRule{{OS: model.OSIOS, URL: "https://evil.com/app"}}})
+
+	var policyErr *model.PolicyViolationError
+	assert.True(t, errors.As(err, &policyErr))
+}
+
+func TestShortener_Shorten_ShouldCheckCanonicalDeviceRuleDestination(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).Times(0)
+	mockPolicy := mocks.NewMockPolicy(controller)
+	mockPolicy.EXPECT().Check("www.yemeksepeti.com").Return(nil).Times(1)
+	mockPolicy.EXPECT().Check("xn--vil-ima.com").Return(&model.PolicyViolationError{Host: "xn--vil-ima.com", Rule: "block xn--vil-ima.com"}).Times(1)
+
+	s := Shortener{DB: mockDB, Policy: mockPolicy}
+	_, err := s.Shorten(longURL, model.LinkOptions{DeviceRules: []model.DeviceRule{{OS: model.OSIOS, URL: "https://ËVIL.com/app"}}})
+
+	var policyErr *model.PolicyViolationError
+	assert.True(t, errors.As(err, &policyErr))
+}
+
+func TestShortener_Shorten_ShouldReturnErrorWhenPolicyRejectsCountryRuleDestination(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).Times(0)
+	mockPolicy := mocks.NewMockPolicy(controller)
+	mockPolicy.EXPECT().Check("www.yemeksepeti.com").Return(nil).Times(1)
+	mockPolicy.EXPECT().Check("evil.com").Return(&model.PolicyViolationError{Host: "evil.com", Rule: "block evil.com"}).Times(1)
+
+	s := Shortener{DB: mockDB, Policy: mockPolicy}
+	_, err := s.Shorten(longURL, model.LinkOptions{CountryRules: []model.CountryRule{{Country: "TR", URL: "https://EVIL.com/tr"}}})
+
+	var policyErr *model.PolicyViolationError
+	assert.True(t, errors.As(err, &policyErr))
+}
+
+func TestShortener_Expand_ShouldReturnRedirectModeOfLink(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{RedirectMode: model.RedirectPermanentRedirect}}, nil).Times(1)
+	mockDB.EXPECT().Hit("05bf184", gomock.Any()).Return(nil).Times(1)
+
+	s := Shortener{DB: mockDB}
+	redirection, err := s.Expand(model.ExpandRequest{Hash: "05bf184"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.Redirection{URL: longURL, Mode: model.RedirectPermanentRedirect, Cacheable: true}, redirection)
 }
 
 func TestShortener_Expand_ShouldReturnErrorWhenCantIncreaseHit(t *testing.T) {
@@ -103,23 +412,178 @@ func TestShortener_Expand_ShouldReturnErrorWhenCantIncreaseHit(t *testing.T) {
 	defer controller.Finish()
 	mockDB := mocks.NewMockDB(controller)
 	mockDB.EXPECT().Get(gomock.Any()).Return(model.RedirectionData{OriginalURL: longURL}, nil).Times(1)
-	mockDB.EXPECT().Hit(gomock.Any()).Return(errors.New("key not found")).Times(1)
+	mockDB.EXPECT().Hit(gomock.Any(), gomock.Any()).Return(errors.New("key not found")).Times(1)
 
 	s := Shortener{DB: mockDB}
 	hash := "05bf184"
-	url, err := s.Expand(hash)
+	url, err := s.Expand(model.ExpandRequest{Hash: hash})
 
 	assert.Error(t, err)
-	assert.Equal(t, "", url)
+	assert.Equal(t, "", url.URL)
+}
+
+func TestShortener_Expand_ShouldOnlyMarkStaticLinksCacheable(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  model.LinkOptions
+		expected bool
+	}{
+		{name: "static", options: model.LinkOptions{}, expected: true},
+		{name: "device rules", options: model.LinkOptions{DeviceRules: []model.DeviceRule{{OS: "ios", URL: longURL}}}},
+		{name: "country rules", options: model.LinkOptions{CountryRules: []model.CountryRule{{Country: "TR", URL: longURL}}}},
+		{name: "variants", options: model.LinkOptions{Variants: []model.Variant{{Name: "a", URL: longURL, Weight: 1}}}},
+		{name: "params", options: model.LinkOptions{Params: map[string]string{"utm_source": "{{referrer_host}}"}}},
+		{name: "max hits", options: model.LinkOptions{MaxHits: 10}},
+		{name: "not after", options: model.LinkOptions{NotAfter: createdAt.Add(time.Hour)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Shortener{DB: db.NewInMemoryDB(), Clock: func() time.Time { return createdAt }}
+			shortURL, err := s.Shorten(longURL, tt.options)
+			assert.Nil(t, err)
+
+			redirection, err := s.Expand(model.ExpandRequest{Hash: strings.TrimPrefix(shortURL, "/")})
+
+			assert.Nil(t, err)
+			assert.Equal(t, tt.expected, redirection.Cacheable)
+		})
+	}
+}
+
+func TestShortener_Expand_ShouldReturnErrorWhenHitLimitReached(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get(gomock.Any()).Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{MaxHits: 1}}, nil).Times(1)
+	mockDB.EXPECT().Hit(gomock.Any(), gomock.Any()).Return(model.ErrHitLimitReached).Times(1)
+
+	s := Shortener{DB: mockDB}
+	url, err := s.Expand(model.ExpandRequest{Hash: "05bf184"})
+
+	assert.ErrorIs(t, err, model.ErrHitLimitReached)
+	assert.Equal(t, "", url.URL)
+}
+
+func TestShortener_Expand_ShouldCheckActivationWindow(t *testing.T) {
+	notBefore := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	notAfter := time.Date(2022, 5, 2, 10, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "before the window", now: notBefore.Add(-time.Second), wantErr: model.ErrLinkNotActive},
+		{name: "at the start of the window", now: notBefore},
+		{name: "within the window", now: notBefore.Add(time.Hour)},
+		{name: "at the end of the window", now: notAfter, wantErr: model.ErrLinkExpired},
+		{name: "after the window", now: notAfter.Add(time.Hour), wantErr: model.ErrLinkExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			mockDB := mocks.NewMockDB(controller)
+			options := model.LinkOptions{NotBefore: notBefore, NotAfter: notAfter}
+			mockDB.EXPECT().Get(gomock.Any()).Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: options}, nil).Times(1)
+			if tt.wantErr == nil {
+				mockDB.EXPECT().Hit(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+			}
+
+			now := tt.now
+			s := Shortener{DB: mockDB, Clock: func() time.Time { return now }}
+			_, err := s.Expand(model.ExpandRequest{Hash: "05bf184"})
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
+
+func TestShortener_Shorten_ShouldStoreHashedPassword(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	var stored model.RedirectionData
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).DoAndReturn(func(_ string, data model.RedirectionData) error {
+		stored = data
+		return nil
+	}).Times(1)
+
+	s := Shortener{DB: mockDB}
+	_, err := s.Shorten(longURL, model.LinkOptions{Password: "s3cret"})
+
+	assert.Nil(t, err)
+	assert.NotEqual(t, "s3cret", stored.Password)
+	assert.True(t, verifyPassword(stored.Password, "s3cret"))
+}
+
+func TestShortener_Expand_ShouldReturnErrorWhenLinkIsPasswordProtected(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	passwordHash, _ := hashPassword("s3cret")
+	mockDB.EXPECT().Get(gomock.Any()).Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{Password: passwordHash}}, nil).Times(1)
+	mockDB.EXPECT().Hit(gomock.Any(), gomock.Any()).Times(0)
+
+	s := Shortener{DB: mockDB}
+	_, err := s.Expand(model.ExpandRequest{Hash: "05bf184"})
+
+	assert.ErrorIs(t, err, model.ErrPasswordRequired)
+}
+
+func TestShortener_Unlock(t *testing.T) {
+	passwordHash, _ := hashPassword("s3cret")
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get(gomock.Any()).Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{Password: passwordHash}}, nil).AnyTimes()
+	mockDB.EXPECT().Hit("05bf184", gomock.Any()).Return(nil).Times(1)
+
+	s := Shortener{DB: mockDB, PasswordAttempts: NewAttemptLimiter(2, time.Minute)}
+
+	_, err := s.Unlock(model.ExpandRequest{Hash: "05bf184"}, "wrong")
+	assert.ErrorIs(t, err, model.ErrInvalidPassword)
+
+	url, err := s.Unlock(model.ExpandRequest{Hash: "05bf184"}, "s3cret")
+	assert.Nil(t, err)
+	assert.Equal(t, longURL, url.URL)
+
+	// the successful attempt resets the attempts
+	for i := 0; i < 2; i++ {
+		_, err = s.Unlock(model.ExpandRequest{Hash: "05bf184"}, "wrong")
+		assert.ErrorIs(t, err, model.ErrInvalidPassword)
+	}
+	_, err = s.Unlock(model.ExpandRequest{Hash: "05bf184"}, "s3cret")
+	assert.ErrorIs(t, err, model.ErrTooManyAttempts)
 }
 
 func TestShortener_List(t *testing.T) {
 	data := map[string]model.RedirectionData{
-		"05bf184": {OriginalURL: longURL, Hits: 5},
+		"05bf184": {OriginalURL: longURL, Hits: 5, BotHits: 2},
+	}
+
+	expectedResult := []model.ListData{
+		{OriginalURL: longURL, Hits: 5, HumanHits: 3, BotHits: 2, Hash: "05bf184"},
+	}
+
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Data().Return(data).Times(1)
+
+	s := Shortener{DB: mockDB}
+	actualResult := s.List(model.ListFilter{})
+	assert.Equal(t, expectedResult, actualResult)
+}
+
+func TestShortener_List_ShouldFilterByTag(t *testing.T) {
+	data := map[string]model.RedirectionData{
+		"05bf184": {OriginalURL: longURL, LinkOptions: model.LinkOptions{Metadata: model.Metadata{Title: "Istanbul", Tags: []string{"food", "campaign"}}}},
+		"8d505df": {OriginalURL: longURL, LinkOptions: model.LinkOptions{Metadata: model.Metadata{Tags: []string{"food"}}}},
 	}
 
 	expectedResult := []model.ListData{
-		{OriginalURL: longURL, Hits: 5, Hash: "05bf184"},
+		{OriginalURL: longURL, Hash: "05bf184", Title: "Istanbul", Tags: []string{"food", "campaign"}},
 	}
 
 	controller := gomock.NewController(t)
@@ -128,7 +592,7 @@ func TestShortener_List(t *testing.T) {
 	mockDB.EXPECT().Data().Return(data).Times(1)
 
 	s := Shortener{DB: mockDB}
-	actualResult := s.List()
+	actualResult := s.List(model.ListFilter{Tag: "Campaign"})
 	assert.Equal(t, expectedResult, actualResult)
 }
 
@@ -143,6 +607,6 @@ func TestShortener_List_ShouldReturnJSONArrayWhenDbIsEmpty(t *testing.T) {
 	mockDB.EXPECT().Data().Return(data).Times(1)
 
 	s := Shortener{DB: mockDB}
-	actualResult := s.List()
+	actualResult := s.List(model.ListFilter{})
 	assert.Equal(t, expectedResult, actualResult)
 }
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/hyperloglog"
+	"fmt"
+	"time"
+)
+
+const (
+	// defaultStatsBuckets is the number of buckets up to To when From is not given.
+	defaultStatsBuckets = 24
+	// maxStatsBuckets limits the length of the series, it is a day of minutes or two months of hours.
+	maxStatsBuckets = 1440
+)
+
+// Stats returns the hit series of the short URL with the given hash on the given domain host in the given workspace.
+// The granularity is an hour and the range is the last day when they are not given, and every bucket in the range
+// is returned including the ones without hits. The unique visitors of the range are estimated from the merged
+// sketches of its buckets. The breakdown is not limited to the range, it is of all hits.
+func (s Shortener) Stats(workspace, host, hash string, query model.StatsQuery) (model.Stats, error) {
+	if query.Granularity == "" {
+		query.Granularity = model.GranularityHour
+	}
+	size, ok := model.GranularityDuration(query.Granularity)
+	if !ok {
+		return model.Stats{}, fmt.Errorf("%w: unknown granularity %q", model.ErrInvalidStatsRange, query.Granularity)
+	}
+
+	to := query.To
+	if to.IsZero() {
+		to = s.now()
+	}
+	from := query.From
+	if from.IsZero() {
+		from = to.Add(-(defaultStatsBuckets - 1) * size)
+	}
+	from, to = from.UTC().Truncate(size), to.UTC().Truncate(size)
+	if from.After(to) {
+		return model.Stats{}, fmt.Errorf("%w: from must be before to", model.ErrInvalidStatsRange)
+	}
+	count := int(to.Sub(from)/size) + 1
+	if count > maxStatsBuckets {
+		return model.Stats{}, fmt.Errorf("%w: more than %d %s buckets", model.ErrInvalidStatsRange, maxStatsBuckets, query.Granularity)
+	}
+
+	data, err := s.DB.Get(s.linkKey(s.findDomain(host), hash))
+	if err != nil {
+		return model.Stats{}, err
+	}
+	if data.Workspace != workspace {
+		return model.Stats{}, workspaceLinkNotFound(hash)
+	}
+
+	stats := model.Stats{
+		Hash:        hash,
+		Granularity: query.Granularity,
+		From:        from,
+		To:          to.Add(size),
+		Series:      make([]model.StatsBucket, count),
+		Breakdown:   data.Breakdown.Breakdown(),
+	}
+	hits, sketches := data.Series.Range(query.Granularity, from, to)
+	var visitors []byte
+	for i := range stats.Series {
+		stats.Series[i] = model.StatsBucket{Time: from.Add(time.Duration(i) * size), Hits: hits[i], Visitors: hyperloglog.Estimate(sketches[i])}
+		stats.Hits += hits[i]
+		visitors = hyperloglog.Merge(visitors, sketches[i])
+	}
+	stats.Visitors = hyperloglog.Estimate(visitors)
+	return stats, nil
+}
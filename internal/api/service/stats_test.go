@@ -0,0 +1,125 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"testing"
+	"time"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var statsNow = time.Date(2022, 6, 2, 9, 30, 0, 0, time.UTC)
+
+func newStatsShortener(t *testing.T, data model.RedirectionData) Shortener {
+	controller := gomock.NewController(t)
+	t.Cleanup(controller.Finish)
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("05bf184").Return(data, nil).AnyTimes()
+	return Shortener{DB: mockDB, Clock: func() time.Time { return statsNow }}
+}
+
+func TestShortener_Stats_ShouldReturnEveryBucketOfRange(t *testing.T) {
+	series := &model.HitSeries{Minutes: map[int64]int{
+		statsNow.Add(-2 * time.Minute).Unix(): 3,
+		statsNow.Unix():                       1,
+		statsNow.Add(-time.Hour).Unix():       7,
+	}}
+	s := newStatsShortener(t, model.RedirectionData{OriginalURL: longURL, Series: series})
+
+	stats, err := s.Stats("", "", "05bf184", model.StatsQuery{
+		From:        statsNow.Add(-150 * time.Second),
+		To:          statsNow.Add(30 * time.Second),
+		Granularity: model.GranularityMinute,
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.Stats{
+		Hash:        "05bf184",
+		Granularity: model.GranularityMinute,
+		From:        statsNow.Add(-3 * time.Minute),
+		To:          statsNow.Add(time.Minute),
+		Hits:        4,
+		Series: []model.StatsBucket{
+			{Time: statsNow.Add(-3 * time.Minute)},
+			{Time: statsNow.Add(-2 * time.Minute), Hits: 3},
+			{Time: statsNow.Add(-time.Minute)},
+			{Time: statsNow, Hits: 1},
+		},
+		Breakdown: model.Breakdown{Referrers: []model.BreakdownEntry{}, Browsers: []model.BreakdownEntry{}, OS: []model.BreakdownEntry{}, Devices: []model.BreakdownEntry{}},
+	}, stats)
+}
+
+func TestShortener_Stats_ShouldReturnLastDayOfHoursByDefault(t *testing.T) {
+	hour := statsNow.Truncate(time.Hour)
+	series := &model.HitSeries{Hours: map[int64]int{hour.Unix(): 2, hour.Add(-23 * time.Hour).Unix(): 5, hour.Add(-24 * time.Hour).Unix(): 9}}
+	s := newStatsShortener(t, model.RedirectionData{OriginalURL: longURL, Series: series})
+
+	stats, err := s.Stats("", "", "05bf184", model.StatsQuery{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.GranularityHour, stats.Granularity)
+	assert.Equal(t, hour.Add(-23*time.Hour), stats.From)
+	assert.Equal(t, hour.Add(time.Hour), stats.To)
+	assert.Len(t, stats.Series, 24)
+	assert.Equal(t, 7, stats.Hits)
+}
+
+func TestShortener_Stats_ShouldReturnErrorWhenQueryIsInvalid(t *testing.T) {
+	s := newStatsShortener(t, model.RedirectionData{OriginalURL: longURL})
+	tests := map[string]model.StatsQuery{
+		"unknown granularity": {Granularity: "week"},
+		"from after to":       {From: statsNow, To: statsNow.Add(-time.Hour)},
+		"too many buckets":    {From: statsNow.Add(-61 * 24 * time.Hour), To: statsNow},
+	}
+
+	for name, query := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.Stats("", "", "05bf184", query)
+			assert.ErrorIs(t, err, model.ErrInvalidStatsRange)
+		})
+	}
+}
+
+func TestShortener_Stats_ShouldReturnNotFoundWhenLinkIsInAnotherWorkspace(t *testing.T) {
+	s := newStatsShortener(t, model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{Workspace: "team-a"}})
+
+	_, err := s.Stats("team-b", "", "05bf184", model.StatsQuery{})
+
+	assert.ErrorIs(t, err, model.ErrLinkNotFound)
+}
+
+func TestShortener_Stats_ShouldReturnBreakdownInDescendingOrderOfHits(t *testing.T) {
+	breakdown := &model.HitBreakdown{
+		Referrers: map[string]int{"t.co": 2, "google.com": 5, model.ReferrerDirect: 2},
+		Browsers:  map[string]int{model.BrowserSafari: 1, model.BrowserChrome: 8},
+		OS:        map[string]int{model.OSIOS: 9},
+	}
+	s := newStatsShortener(t, model.RedirectionData{OriginalURL: longURL, Breakdown: breakdown})
+
+	stats, err := s.Stats("", "", "05bf184", model.StatsQuery{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.Breakdown{
+		Referrers: []model.BreakdownEntry{{Value: "google.com", Hits: 5}, {Value: model.ReferrerDirect, Hits: 2}, {Value: "t.co", Hits: 2}},
+		Browsers:  []model.BreakdownEntry{{Value: model.BrowserChrome, Hits: 8}, {Value: model.BrowserSafari, Hits: 1}},
+		OS:        []model.BreakdownEntry{{Value: model.OSIOS, Hits: 9}},
+		Devices:   []model.BreakdownEntry{},
+	}, stats.Breakdown)
+}
+
+func TestShortener_Expand_ShouldHitWithReferrerHostAndUserAgent(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	agent := model.UserAgent{OS: model.OSAndroid, Device: model.DeviceMobile, Browser: model.BrowserChrome}
+	mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL}, nil).Times(1)
+	mockDB.EXPECT().Hit("05bf184", model.Hit{Time: statsNow, ReferrerHost: "news.example.com", UserAgent: agent}).Return(nil).Times(1)
+
+	s := Shortener{DB: mockDB, Clock: func() time.Time { return statsNow }}
+	_, err := s.Expand(model.ExpandRequest{Hash: "05bf184", Referrer: "https://News.Example.com/a?b=c", UserAgent: agent})
+
+	assert.Nil(t, err)
+}
@@ -0,0 +1,19 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/safehash"
+	"fmt"
+)
+
+// Suggest returns the nearest safe hash to the given mistyped hash which has a link on the short URL domain with the
+// given host. model.ErrLinkNotFound is returned when none of the nearest hashes has a link.
+func (s Shortener) Suggest(host, hash string) (string, error) {
+	domain := s.findDomain(host)
+	for _, candidate := range safehash.Candidates(hash) {
+		if _, err := s.DB.Get(s.linkKey(domain, candidate)); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("%s %w", hash, model.ErrLinkNotFound)
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/safehash"
+	"errors"
+	"testing"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShortener_Shorten_ShouldCreateSafeHashWhenSafeHashesIsSet(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	s := Shortener{DB: mockDB, SafeHashes: true}
+	shortURL, err := s.Shorten(longURL, model.LinkOptions{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "/kduf6s5h", shortURL)
+	assert.True(t, safehash.Valid(shortURL[1:]))
+}
+
+func TestShortener_Suggest(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("kduf6s5h").Return(model.RedirectionData{OriginalURL: longURL}, nil).AnyTimes()
+	mockDB.EXPECT().Get(gomock.Not("kduf6s5h")).Return(model.RedirectionData{}, errors.New("not found")).AnyTimes()
+
+	s := Shortener{DB: mockDB}
+	suggestion, err := s.Suggest("", "kduf7s5h")
+	assert.Nil(t, err)
+	assert.Equal(t, "kduf6s5h", suggestion)
+
+	suggestion, err = s.Suggest("", "kdfu6s5h")
+	assert.Nil(t, err)
+	assert.Equal(t, "kduf6s5h", suggestion)
+
+	_, err = s.Suggest("", "23456787")
+	assert.ErrorIs(t, err, model.ErrLinkNotFound)
+}
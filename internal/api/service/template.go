@@ -0,0 +1,143 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"fmt"
+	neturl "net/url"
+	"strings"
+	"time"
+)
+
+const (
+	templateOpen  = "{{"
+	templateClose = "}}"
+	// templateQueryPrefix prefixes the variables which are rendered from the query of the request, e.g. {{query.source}}.
+	templateQueryPrefix = "query."
+	// sourceParam is the query parameter of the request which the source variable is rendered from.
+	sourceParam = "source"
+)
+
+// templateContext is the information about the redirect which the param templates are rendered from.
+type templateContext struct {
+	req model.ExpandRequest
+	now time.Time
+}
+
+// templateVariables are the variables which can be used in the param templates.
+var templateVariables = map[string]func(templateContext) string{
+	"hash":          func(c templateContext) string { return c.req.Hash },
+	"domain":        func(c templateContext) string { return strings.ToLower(c.req.Host) },
+	"referrer_host": func(c templateContext) string { return referrerHost(c.req.Referrer) },
+	"source":        renderSource,
+	"country":       func(c templateContext) string { return c.req.Country },
+	"date":          func(c templateContext) string { return c.now.UTC().Format("2006-01-02") },
+	"time":          func(c templateContext) string { return c.now.UTC().Format(time.RFC3339) },
+}
+
+// renderSource renders the source of the visitor, which is the source query parameter of the request,
+// e.g. /abc1234?source=newsletter, or the referrer host when the request has none.
+func renderSource(c templateContext) string {
+	if source := c.req.Query.Get(sourceParam); source != "" {
+		return source
+	}
+	return referrerHost(c.req.Referrer)
+}
+
+// templatePart is either a literal text or a variable of a param template.
+type templatePart struct {
+	text     string
+	variable string
+}
+
+// render renders the part in the given context.
+func (p templatePart) render(c templateContext) string {
+	if p.variable == "" {
+		return p.text
+	}
+	if strings.HasPrefix(p.variable, templateQueryPrefix) {
+		return c.req.Query.Get(strings.TrimPrefix(p.variable, templateQueryPrefix))
+	}
+	return templateVariables[p.variable](c)
+}
+
+// parseTemplate splits the given template to its literal texts and variables.
+func parseTemplate(template string) ([]templatePart, error) {
+	var parts []templatePart
+	for template != "" {
+		start := strings.Index(template, templateOpen)
+		if start < 0 {
+			parts = append(parts, templatePart{text: template})
+			break
+		}
+		if start > 0 {
+			parts = append(parts, templatePart{text: template[:start]})
+		}
+
+		template = template[start+len(templateOpen):]
+		end := strings.Index(template, templateClose)
+		if end < 0 {
+			return nil, fmt.Errorf("%w: unclosed %s", model.ErrInvalidTemplate, templateOpen)
+		}
+
+		variable := strings.TrimSpace(template[:end])
+		if !isTemplateVariable(variable) {
+			return nil, fmt.Errorf("%w: unknown variable %q", model.ErrInvalidTemplate, variable)
+		}
+		parts = append(parts, templatePart{variable: variable})
+		template = template[end+len(templateClose):]
+	}
+	return parts, nil
+}
+
+// isTemplateVariable reports whether the given name is a built-in variable or a query variable.
+func isTemplateVariable(name string) bool {
+	if strings.HasPrefix(name, templateQueryPrefix) {
+		return len(name) > len(templateQueryPrefix)
+	}
+	_, ok := templateVariables[name]
+	return ok
+}
+
+// validateParams checks that every param has a name and a template which can be rendered.
+func validateParams(params map[string]string) error {
+	for name, template := range params {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("%w: empty param name", model.ErrInvalidTemplate)
+		}
+		if _, err := parseTemplate(template); err != nil {
+			return fmt.Errorf("param %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// applyParams renders the param templates and sets them in the query of the destination.
+// The params replace the same parameters of the destination, and the params which are rendered empty are skipped.
+func applyParams(destination string, params map[string]string, c templateContext) (string, error) {
+	if len(params) == 0 {
+		return destination, nil
+	}
+
+	u, err := neturl.Parse(destination)
+	if err != nil {
+		return "", err
+	}
+
+	query := u.Query()
+	for name, template := range params {
+		parts, err := parseTemplate(template)
+		if err != nil {
+			return "", err
+		}
+		var value strings.Builder
+		for _, part := range parts {
+			value.WriteString(part.render(c))
+		}
+		if value.Len() > 0 {
+			query.Set(name, value.String())
+		}
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String(), nil
+}
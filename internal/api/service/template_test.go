@@ -0,0 +1,93 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  map[string]string
+		wantErr bool
+	}{
+		{name: "no params", params: nil, wantErr: false},
+		{name: "literal value", params: map[string]string{"utm_medium": "social"}, wantErr: false},
+		{name: "built-in variables", params: map[string]string{"utm_source": "{{referrer_host}}", "utm_campaign": "launch-{{ date }}"}, wantErr: false},
+		{name: "query variable", params: map[string]string{"utm_source": "{{query.source}}"}, wantErr: false},
+		{name: "source variable", params: map[string]string{"utm_source": "{{source}}"}, wantErr: false},
+		{name: "unknown variable", params: map[string]string{"utm_source": "{{campaign}}"}, wantErr: true},
+		{name: "empty query variable", params: map[string]string{"utm_source": "{{query.}}"}, wantErr: true},
+		{name: "unclosed variable", params: map[string]string{"utm_source": "{{hash"}, wantErr: true},
+		{name: "empty param name", params: map[string]string{" ": "social"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateParams(tt.params)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, model.ErrInvalidTemplate)
+			} else {
+				assert.Nil(t, err)
+			}
+		})
+	}
+}
+
+func TestApplyParams(t *testing.T) {
+	c := templateContext{
+		req: model.ExpandRequest{
+			Host:     "Short.example",
+			Hash:     "05bf184",
+			Query:    url.Values{"source": {"newsletter"}},
+			Referrer: "https://News.example.com/article?id=1",
+		},
+		now: time.Date(2022, 6, 1, 9, 30, 0, 0, time.UTC),
+	}
+	params := map[string]string{
+		"utm_source":   "{{referrer_host}}",
+		"utm_medium":   "{{query.source}}",
+		"utm_campaign": "{{domain}}-{{hash}}-{{date}}",
+		"utm_term":     "{{query.term}}",
+		"lang":         "en",
+		"visited":      "{{time}}",
+	}
+
+	actual, err := applyParams("https://example.com/?lang=tr&id=2", params, c)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "https://example.com/?id=2&lang=en&utm_campaign=short.example-05bf184-2022-06-01&utm_medium=newsletter&utm_source=news.example.com&visited=2022-06-01T09%3A30%3A00Z", actual)
+}
+
+func TestApplyParams_ShouldRenderSource(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      model.ExpandRequest
+		expected string
+	}{
+		{name: "source param", req: model.ExpandRequest{Query: url.Values{"source": {"newsletter"}}, Referrer: "https://news.example.com/"}, expected: "newsletter"},
+		{name: "referrer host", req: model.ExpandRequest{Referrer: "https://News.example.com/article"}, expected: "news.example.com"},
+		{name: "no source", req: model.ExpandRequest{}, expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := applyParams("https://example.com/", map[string]string{"utm_source": "{{source}}"}, templateContext{req: tt.req})
+
+			assert.Nil(t, err)
+			u, _ := url.Parse(actual)
+			assert.Equal(t, tt.expected, u.Query().Get("utm_source"))
+		})
+	}
+}
+
+func TestShortener_Shorten_ShouldReturnErrorWhenParamTemplateIsInvalid(t *testing.T) {
+	s := Shortener{}
+	_, err := s.Shorten(longURL, model.LinkOptions{Params: map[string]string{"utm_source": "{{campaign}}"}})
+
+	assert.ErrorIs(t, err, model.ErrInvalidTemplate)
+}
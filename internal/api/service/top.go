@@ -0,0 +1,149 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/topk"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// minuteSlots and hourSlots are the lengths of the rings of the sliding windows, the windows up to an hour are
+	// counted in minutes and the longer ones in hours.
+	minuteSlots = 60
+	hourSlots   = 24
+	// MaxTopWindow is the longest sliding window of the top links.
+	MaxTopWindow = hourSlots * time.Hour
+)
+
+// TopLinks keeps the links with the most hits of each workspace for their lifetime, and for the sliding windows
+// up to MaxTopWindow in a ring of minutes and a ring of hours. The hits are counted in space-saving sketches of
+// Capacity links, so the memory stays flat regardless of the number of the links.
+type TopLinks struct {
+	Capacity   int
+	workspaces map[string]*workspaceTop
+	mutex      sync.Mutex
+}
+
+// workspaceTop are the top links of a workspace.
+type workspaceTop struct {
+	lifetime *topk.Sketch
+	minutes  [minuteSlots]topSlot
+	hours    [hourSlots]topSlot
+}
+
+// topSlot are the hits in the time bucket which starts at the Unix time start.
+type topSlot struct {
+	start  int64
+	sketch *topk.Sketch
+}
+
+// NewTopLinks creates new top links which count the hits of up to capacity links in each sketch.
+func NewTopLinks(capacity int) *TopLinks {
+	return &TopLinks{Capacity: capacity, workspaces: make(map[string]*workspaceTop)}
+}
+
+// Restore counts the lifetime hits of the given links, it is meant to be called once after the DB is restored.
+func (t *TopLinks) Restore(data map[string]model.RedirectionData) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	for key, link := range data {
+		if link.Hits > 0 {
+			t.workspace(link.Workspace).lifetime.Add(key, link.Hits)
+		}
+	}
+}
+
+// Hit counts a hit of the link with the given DB key in the given workspace at the given time.
+func (t *TopLinks) Hit(workspace, key string, now time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	w := t.workspace(workspace)
+	w.lifetime.Add(key, 1)
+	if s := t.slot(w.minutes[:], now, time.Minute); s != nil {
+		s.sketch.Add(key, 1)
+	}
+	if s := t.slot(w.hours[:], now, time.Hour); s != nil {
+		s.sketch.Add(key, 1)
+	}
+}
+
+// Top returns the n links of the given workspace with the most hits in the window up to the given time, zero window
+// is their lifetime. The window is rounded up to minutes when it is up to an hour, and to hours otherwise.
+func (t *TopLinks) Top(workspace string, n int, window time.Duration, now time.Time) []topk.Entry {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	w, ok := t.workspaces[workspace]
+	if !ok {
+		return nil
+	}
+	if window <= 0 {
+		return topk.Top(n, w.lifetime)
+	}
+
+	slots, size := w.hours[:], time.Hour
+	if window <= time.Hour {
+		slots, size = w.minutes[:], time.Minute
+	}
+	current := now.Truncate(size)
+	var sketches []*topk.Sketch
+	for i := time.Duration(0); i*size < window && int(i) < len(slots); i++ {
+		start := current.Add(-i * size)
+		if s := slots[slotIndex(start, size, len(slots))]; s.sketch != nil && s.start == start.Unix() {
+			sketches = append(sketches, s.sketch)
+		}
+	}
+	return topk.Top(n, sketches...)
+}
+
+// workspace returns the top links of the given workspace, it creates them for a new workspace.
+func (t *TopLinks) workspace(workspace string) *workspaceTop {
+	w, ok := t.workspaces[workspace]
+	if !ok {
+		w = &workspaceTop{lifetime: topk.New(t.Capacity)}
+		t.workspaces[workspace] = w
+	}
+	return w
+}
+
+// slot returns the slot of the ring for the bucket of the given size which contains the given time. The slot is
+// reset when it still has the hits of an earlier round of the ring, and nil is returned when the time is older than the ring.
+func (t *TopLinks) slot(slots []topSlot, now time.Time, size time.Duration) *topSlot {
+	start := now.Truncate(size)
+	s := &slots[slotIndex(start, size, len(slots))]
+	if s.sketch != nil && s.start > start.Unix() {
+		return nil
+	}
+	if s.sketch == nil || s.start != start.Unix() {
+		*s = topSlot{start: start.Unix(), sketch: topk.New(t.Capacity)}
+	}
+	return s
+}
+
+// slotIndex returns the index of the slot of the bucket which starts at the given time in a ring of the given length.
+func slotIndex(start time.Time, size time.Duration, length int) int {
+	return int((start.Unix() / int64(size/time.Second)) % int64(length))
+}
+
+// Top returns the n links of the given workspace with the most hits in the given window, zero window is their lifetime.
+// The hits of a link are overestimated when more links are hit in the window than the capacity of the top links.
+func (s Shortener) Top(workspace string, n int, window time.Duration) ([]model.TopLink, error) {
+	if window > MaxTopWindow {
+		return nil, fmt.Errorf("%w: window is longer than %s", model.ErrInvalidStatsRange, MaxTopWindow)
+	}
+
+	top := []model.TopLink{}
+	if s.TopLinks == nil {
+		return top, nil
+	}
+	for _, e := range s.TopLinks.Top(workspace, n, window, s.now()) {
+		data, err := s.DB.Get(e.Key)
+		if err != nil {
+			continue
+		}
+		_, hash := splitKey(e.Key)
+		top = append(top, model.TopLink{Hash: hash, Domain: data.Domain, OriginalURL: data.OriginalURL, Title: data.Title, Hits: e.Count})
+	}
+	return top, nil
+}
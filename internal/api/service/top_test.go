@@ -0,0 +1,103 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/topk"
+	"testing"
+	"time"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTopLinks_Top(t *testing.T) {
+	now := time.Date(2022, 6, 2, 9, 30, 0, 0, time.UTC)
+	top := NewTopLinks(10)
+	top.Restore(map[string]model.RedirectionData{
+		"old":       {Hits: 50},
+		"b.co/team": {Hits: 70, LinkOptions: model.LinkOptions{Workspace: "team-a"}},
+		"unused":    {},
+	})
+	for i := 0; i < 3; i++ {
+		top.Hit("", "a", now.Add(-2*time.Hour))
+	}
+	for i := 0; i < 2; i++ {
+		top.Hit("", "b", now.Add(-30*time.Minute))
+	}
+	top.Hit("", "c", now)
+	top.Hit("", "a", now.Add(-25*time.Hour))
+
+	tests := []struct {
+		window   time.Duration
+		expected []topk.Entry
+	}{
+		{window: 0, expected: []topk.Entry{{Key: "old", Count: 50}, {Key: "a", Count: 4}, {Key: "b", Count: 2}, {Key: "c", Count: 1}}},
+		{window: time.Minute, expected: []topk.Entry{{Key: "c", Count: 1}}},
+		{window: 31 * time.Minute, expected: []topk.Entry{{Key: "b", Count: 2}, {Key: "c", Count: 1}}},
+		{window: time.Hour, expected: []topk.Entry{{Key: "b", Count: 2}, {Key: "c", Count: 1}}},
+		{window: 3 * time.Hour, expected: []topk.Entry{{Key: "a", Count: 3}, {Key: "b", Count: 2}, {Key: "c", Count: 1}}},
+		{window: 24 * time.Hour, expected: []topk.Entry{{Key: "a", Count: 3}, {Key: "b", Count: 2}, {Key: "c", Count: 1}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.window.String(), func(t *testing.T) {
+			assert.Equal(t, tt.expected, top.Top("", 10, tt.window, now))
+		})
+	}
+
+	assert.Equal(t, []topk.Entry{{Key: "old", Count: 50}, {Key: "a", Count: 4}}, top.Top("", 2, 0, now))
+	assert.Equal(t, []topk.Entry{{Key: "b.co/team", Count: 70}}, top.Top("team-a", 10, 0, now))
+	assert.Empty(t, top.Top("team-a", 10, time.Hour, now))
+	assert.Empty(t, top.Top("team-b", 10, 0, now))
+}
+
+func TestTopLinks_Top_ShouldDropHitsOfEarlierRoundsOfRing(t *testing.T) {
+	now := time.Date(2022, 6, 2, 9, 30, 0, 0, time.UTC)
+	top := NewTopLinks(10)
+	top.Hit("", "a", now.Add(-time.Hour))
+	top.Hit("", "b", now)
+
+	assert.Equal(t, []topk.Entry{{Key: "b", Count: 1}}, top.Top("", 10, time.Minute, now))
+	assert.Equal(t, []topk.Entry{{Key: "b", Count: 1}}, top.Top("", 10, time.Hour, now))
+	assert.Equal(t, []topk.Entry{{Key: "a", Count: 1}, {Key: "b", Count: 1}}, top.Top("", 10, 2*time.Hour, now))
+}
+
+func TestShortener_Top(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("b.co/05bf184").Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{Domain: "b.co"}}, nil).Times(1)
+	mockDB.EXPECT().Get("8d505df").Return(model.RedirectionData{}, model.ErrLinkNotFound).Times(1)
+	top := NewTopLinks(10)
+	top.Hit("", "b.co/05bf184", statsNow)
+	top.Hit("", "b.co/05bf184", statsNow)
+	top.Hit("", "8d505df", statsNow)
+
+	s := Shortener{DB: mockDB, TopLinks: top, Clock: func() time.Time { return statsNow }}
+	links, err := s.Top("", 10, time.Hour)
+
+	assert.Nil(t, err)
+	assert.Equal(t, []model.TopLink{{Hash: "05bf184", Domain: "b.co", OriginalURL: longURL, Hits: 2}}, links)
+}
+
+func TestShortener_Top_ShouldReturnErrorWhenWindowIsTooLong(t *testing.T) {
+	s := Shortener{TopLinks: NewTopLinks(10)}
+	_, err := s.Top("", 10, MaxTopWindow+time.Hour)
+
+	assert.ErrorIs(t, err, model.ErrInvalidStatsRange)
+}
+
+func TestShortener_Expand_ShouldCountHitInTopLinks(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{Workspace: "team-a"}}, nil).Times(1)
+	mockDB.EXPECT().Hit("05bf184", gomock.Any()).Return(nil).Times(1)
+
+	s := Shortener{DB: mockDB, TopLinks: NewTopLinks(10), Clock: func() time.Time { return statsNow }}
+	_, err := s.Expand(model.ExpandRequest{Hash: "05bf184"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []topk.Entry{{Key: "05bf184", Count: 1}}, s.TopLinks.Top("team-a", 10, time.Minute, statsNow))
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"math/rand"
+)
+
+// selectVariant returns the variant with the assigned name, or picks a variant by weight if there is no such variant.
+// random returns a random number in [0, n).
+func selectVariant(variants []model.Variant, assigned string, random func(n int) int) model.Variant {
+	totalWeight := 0
+	for _, variant := range variants {
+		if variant.Name == assigned {
+			return variant
+		}
+		totalWeight += variant.Weight
+	}
+
+	n := random(totalWeight)
+	for _, variant := range variants {
+		if n < variant.Weight {
+			return variant
+		}
+		n -= variant.Weight
+	}
+	return variants[len(variants)-1]
+}
+
+// random returns a random number in [0, n) from the Random function of the shortener.
+func (s Shortener) random(n int) int {
+	if s.Random == nil {
+		return rand.Intn(n)
+	}
+	return s.Random(n)
+}
+
+// variantStats returns the variants of the link with their hit counts.
+func variantStats(data model.RedirectionData) []model.VariantStats {
+	if len(data.Variants) == 0 {
+		return nil
+	}
+	stats := make([]model.VariantStats, 0, len(data.Variants))
+	for _, variant := range data.Variants {
+		stats = append(stats, model.VariantStats{Variant: variant, Hits: data.VariantHits.Get(variant.Name)})
+	}
+	return stats
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"errors"
+	"testing"
+	"time"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+var testVariants = []model.Variant{
+	{Name: "a", URL: "https://example.com/a", Weight: 70},
+	{Name: "b", URL: "https://example.com/b", Weight: 30},
+}
+
+func TestSelectVariant(t *testing.T) {
+	tests := []struct {
+		name     string
+		assigned string
+		random   int
+		expected string
+	}{
+		{name: "first variant by weight", random: 0, expected: "a"},
+		{name: "last number of the first variant", random: 69, expected: "a"},
+		{name: "second variant by weight", random: 70, expected: "b"},
+		{name: "assigned variant", assigned: "b", random: 0, expected: "b"},
+		{name: "removed assigned variant", assigned: "c", random: 99, expected: "b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			variant := selectVariant(testVariants, tt.assigned, func(n int) int {
+				assert.Equal(t, 100, n)
+				return tt.random
+			})
+			assert.Equal(t, tt.expected, variant.Name)
+		})
+	}
+}
+
+func TestShortener_Expand_ShouldHitAssignedVariant(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: model.LinkOptions{Variants: testVariants}}, nil).Times(1)
+	mockDB.EXPECT().Hit("05bf184", model.Hit{Variant: "b", Time: createdAt}).Return(nil).Times(1)
+
+	s := Shortener{DB: mockDB, Random: func(n int) int { return 80 }, Clock: func() time.Time { return createdAt }}
+	redirection, err := s.Expand(model.ExpandRequest{Hash: "05bf184"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.Redirection{URL: "https://example.com/b", Variant: "b"}, redirection)
+}
+
+func TestShortener_Expand_ShouldPreferDeviceRulesToVariants(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	options := model.LinkOptions{Variants: testVariants, DeviceRules: []model.DeviceRule{{OS: model.OSIOS, URL: "https://apps.apple.com/app/id1"}}}
+	mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL, LinkOptions: options}, nil).Times(1)
+	mockDB.EXPECT().Hit("05bf184", model.Hit{Time: createdAt, UserAgent: model.UserAgent{OS: model.OSIOS}}).Return(nil).Times(1)
+
+	s := Shortener{DB: mockDB, Clock: func() time.Time { return createdAt }}
+	redirection, err := s.Expand(model.ExpandRequest{Hash: "05bf184", UserAgent: model.UserAgent{OS: model.OSIOS}})
+
+	assert.Nil(t, err)
+	assert.Equal(t, model.Redirection{URL: "https://apps.apple.com/app/id1"}, redirection)
+}
+
+func TestShortener_List_ShouldReturnVariantHits(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Data().Return(map[string]model.RedirectionData{
+		"05bf184": {OriginalURL: longURL, Hits: 5, VariantHits: model.NewHitCounts(map[string]int{"a": 5}), LinkOptions: model.LinkOptions{Variants: testVariants}},
+	}).Times(1)
+
+	s := Shortener{DB: mockDB}
+	list := s.List(model.ListFilter{})
+
+	assert.Equal(t, []model.VariantStats{{Variant: testVariants[0], Hits: 5}, {Variant: testVariants[1], Hits: 0}}, list[0].Variants)
+}
+
+func TestShortener_Shorten_ShouldReturnErrorWhenPolicyRejectsVariantDestination(t *testing.T) {
+	controller := gomock.NewController(t)
+	defer controller.Finish()
+	mockDB := mocks.NewMockDB(controller)
+	mockDB.EXPECT().Set(gomock.Any(), gomock.Any()).Times(0)
+	mockPolicy := mocks.NewMockPolicy(controller)
+	mockPolicy.EXPECT().Check("www.yemeksepeti.com").Return(nil).Times(2)
+	mockPolicy.EXPECT().Check("evil.com").Return(&model.PolicyViolationError{Host: "evil.com", Rule: "block evil.com"}).Times(1)
+
+	s := Shortener{DB: mockDB, Policy: mockPolicy}
+	_, err := s.Shorten(longURL, model.LinkOptions{Variants: []model.Variant{{Name: "a", URL: longURL, Weight: 1}, {Name: "b", URL: "https://Evil.com/b", Weight: 1}}})
+
+	var policyErr *model.PolicyViolationError
+	assert.True(t, errors.As(err, &policyErr))
+}
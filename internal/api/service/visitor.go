@@ -0,0 +1,38 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"time"
+)
+
+// Fingerprinter creates the privacy preserving fingerprints of the visitors to estimate the unique visitors.
+// A fingerprint is a keyed hash of the IP and the user agent of the visitor, and the key is derived from the secret
+// and the day of the visit. So the fingerprints can not be reversed to the IPs without the secret, and the visits
+// of the same visitor can not be linked across days.
+type Fingerprinter struct {
+	secret []byte
+}
+
+// NewFingerprinter creates a new fingerprinter with the given secret.
+func NewFingerprinter(secret []byte) *Fingerprinter {
+	return &Fingerprinter{secret: secret}
+}
+
+// Fingerprint returns the non-zero fingerprint of the visitor with the given IP and user agent on the day of the given time.
+func (f *Fingerprinter) Fingerprint(ip, userAgent string, t time.Time) uint64 {
+	day := hmac.New(sha256.New, f.secret)
+	day.Write([]byte(t.UTC().Format("2006-01-02")))
+
+	visitor := hmac.New(sha256.New, day.Sum(nil))
+	visitor.Write([]byte(ip))
+	visitor.Write([]byte{0})
+	visitor.Write([]byte(userAgent))
+
+	fingerprint := binary.BigEndian.Uint64(visitor.Sum(nil))
+	if fingerprint == 0 {
+		return 1
+	}
+	return fingerprint
+}
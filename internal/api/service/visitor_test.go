@@ -0,0 +1,55 @@
+package service
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"testing"
+	"time"
+
+	mocks "dh-url-shortener/.mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+const testUserAgent = "Mozilla/5.0 (iPhone; CPU iPhone OS 15_5 like Mac OS X)"
+
+func TestFingerprinter_Fingerprint(t *testing.T) {
+	f := NewFingerprinter([]byte("secret"))
+	day := time.Date(2022, 6, 2, 9, 30, 0, 0, time.UTC)
+	fingerprint := f.Fingerprint("203.0.113.7", testUserAgent, day)
+
+	assert.NotZero(t, fingerprint)
+	assert.Equal(t, fingerprint, f.Fingerprint("203.0.113.7", testUserAgent, day.Add(14*time.Hour)))
+	assert.NotEqual(t, fingerprint, f.Fingerprint("203.0.113.7", testUserAgent, day.Add(15*time.Hour)))
+	assert.NotEqual(t, fingerprint, f.Fingerprint("203.0.113.8", testUserAgent, day))
+	assert.NotEqual(t, fingerprint, f.Fingerprint("203.0.113.7", "curl/7.79.1", day))
+	assert.NotEqual(t, fingerprint, NewFingerprinter([]byte("other")).Fingerprint("203.0.113.7", testUserAgent, day))
+}
+
+func TestShortener_Expand_ShouldHitWithVisitorFingerprint(t *testing.T) {
+	tests := []struct {
+		name            string
+		visitors        *Fingerprinter
+		clientIP        string
+		expectedVisitor uint64
+	}{
+		{name: "fingerprinted", visitors: NewFingerprinter([]byte("secret")), clientIP: "203.0.113.7", expectedVisitor: NewFingerprinter([]byte("secret")).Fingerprint("203.0.113.7", testUserAgent, statsNow)},
+		{name: "unknown ip", visitors: NewFingerprinter([]byte("secret"))},
+		{name: "not fingerprinted", clientIP: "203.0.113.7"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			controller := gomock.NewController(t)
+			defer controller.Finish()
+			mockDB := mocks.NewMockDB(controller)
+			mockDB.EXPECT().Get("05bf184").Return(model.RedirectionData{OriginalURL: longURL}, nil).Times(1)
+			mockDB.EXPECT().Hit("05bf184", model.Hit{Time: statsNow, Visitor: tt.expectedVisitor}).Return(nil).Times(1)
+
+			s := Shortener{DB: mockDB, Visitors: tt.visitors, Clock: func() time.Time { return statsNow }}
+			_, err := s.Expand(model.ExpandRequest{Hash: "05bf184", ClientIP: tt.clientIP, RawUserAgent: testUserAgent})
+
+			assert.Nil(t, err)
+		})
+	}
+}
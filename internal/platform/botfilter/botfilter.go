@@ -0,0 +1,113 @@
+package botfilter
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// defaultSignatures are the User-Agent substrings of the common crawlers, link unfurlers, uptime checkers and HTTP clients.
+var defaultSignatures = []string{
+	// the generic names most crawlers carry, e.g. Googlebot, bingbot, AhrefsBot and Applebot
+	"bot",
+	"crawler",
+	"spider",
+	"slurp",
+	// the link unfurlers of the chat apps and social networks which do not say bot
+	"facebookexternalhit",
+	"facebookcatalog",
+	"whatsapp",
+	"skypeuripreview",
+	"embedly",
+	"iframely",
+	"vkshare",
+	"redditbot",
+	// the uptime checkers and the monitoring services
+	"pingdom",
+	"uptimerobot",
+	"statuscake",
+	"site24x7",
+	"newrelicpinger",
+	"datadog",
+	"checkly",
+	"better uptime",
+	// the headless browsers and the HTTP clients of the scripts
+	"headlesschrome",
+	"phantomjs",
+	"curl/",
+	"wget/",
+	"python-requests",
+	"python-urllib",
+	"aiohttp",
+	"go-http-client",
+	"java/",
+	"okhttp",
+	"axios/",
+	"node-fetch",
+	"libwww-perl",
+	"httpclient",
+}
+
+// Filter classifies the User-Agent headers of the bots by their signatures, which are matched case-insensitively
+// as substrings.
+type Filter struct {
+	signatures []string
+}
+
+// Default creates a filter of the signatures of the common crawlers, link unfurlers, uptime checkers and HTTP clients.
+func Default() *Filter {
+	return New(defaultSignatures...)
+}
+
+// Open loads the signatures from the file at the given path.
+func Open(path string) (*Filter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse parses the signatures from the given reader, one signature per line. Empty lines and lines starting with # are ignored.
+func Parse(r io.Reader) (*Filter, error) {
+	filter := &Filter{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		filter.signatures = append(filter.signatures, strings.ToLower(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// New creates a filter of the given signatures.
+func New(signatures ...string) *Filter {
+	filter := &Filter{}
+	for _, signature := range signatures {
+		if signature = strings.TrimSpace(signature); signature != "" {
+			filter.signatures = append(filter.signatures, strings.ToLower(signature))
+		}
+	}
+	return filter
+}
+
+// IsBot reports whether the given User-Agent header is of a bot. The empty header is of a bot, since every browser sends one.
+func (f *Filter) IsBot(userAgent string) bool {
+	userAgent = strings.ToLower(strings.TrimSpace(userAgent))
+	if userAgent == "" {
+		return true
+	}
+	for _, signature := range f.signatures {
+		if strings.Contains(userAgent, signature) {
+			return true
+		}
+	}
+	return false
+}
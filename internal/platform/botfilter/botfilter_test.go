@@ -0,0 +1,69 @@
+package botfilter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefault_IsBot(t *testing.T) {
+	filter := Default()
+	tests := []struct {
+		userAgent string
+		bot       bool
+	}{
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 15_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.5 Mobile/15E148 Safari/604.1", false},
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/102.0.5005.63 Safari/537.36 Edg/102.0.1245.39", false},
+		{"Mozilla/5.0 (Linux; Android 12; Pixel 6) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/102.0.5005.78 Mobile Safari/537.36", false},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", true},
+		{"Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)", true},
+		{"Twitterbot/1.0", true},
+		{"facebookexternalhit/1.1 (+http://www.facebook.com/externalhit_uatext.php)", true},
+		{"WhatsApp/2.22.11.78 A", true},
+		{"Mozilla/5.0 (compatible; UptimeRobot/2.0; http://www.uptimerobot.com/)", true},
+		{"Pingdom.com_bot_version_1.4_(http://www.pingdom.com/)", true},
+		{"Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/102.0.5005.61 Safari/537.36", true},
+		{"curl/7.79.1", true},
+		{"Go-http-client/1.1", true},
+		{"python-requests/2.28.0", true},
+		{"", true},
+		{" ", true},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.bot, filter.IsBot(tt.userAgent), tt.userAgent)
+	}
+}
+
+func TestParse(t *testing.T) {
+	filter, err := Parse(strings.NewReader(`
+# link unfurlers
+Slackbot
+
+TwitterBot
+`))
+	assert.Nil(t, err)
+
+	assert.True(t, filter.IsBot("Slackbot-LinkExpanding 1.0 (+https://api.slack.com/robots)"))
+	assert.True(t, filter.IsBot("Twitterbot/1.0"))
+	assert.False(t, filter.IsBot("curl/7.79.1"))
+	assert.True(t, filter.IsBot(""))
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bots.txt")
+	assert.Nil(t, os.WriteFile(path, []byte("checkly\n"), 0600))
+
+	filter, err := Open(path)
+
+	assert.Nil(t, err)
+	assert.True(t, filter.IsBot("Checkly/1.0 (https://www.checklyhq.com)"))
+	assert.False(t, filter.IsBot("Googlebot/2.1"))
+}
+
+func TestOpen_ShouldReturnErrorWhenFileDoesNotExist(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.NotNil(t, err)
+}
@@ -2,7 +2,9 @@ package db
 
 import (
 	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/hyperloglog"
 	"errors"
+	"fmt"
 	"sync"
 )
 
@@ -29,7 +31,7 @@ func (i *InMemoryDB) Get(key string) (model.RedirectionData, error) {
 	if value, ok := i.data[key]; ok {
 		return value, nil
 	}
-	return model.RedirectionData{}, errors.New(key + " not found")
+	return model.RedirectionData{}, fmt.Errorf("%s %w", key, model.ErrLinkNotFound)
 }
 
 // Set stores a model.RedirectionData in the DB with the given key
@@ -43,29 +45,78 @@ func (i *InMemoryDB) Set(key string, value model.RedirectionData) error {
 	return nil
 }
 
-// Hit increments the hit count of the model.RedirectionData with the given key
-func (i *InMemoryDB) Hit(key string) error {
+// Hit atomically compares the hit count of the model.RedirectionData with the given key against its MaxHits
+// and increments it with the hit count of the variant of the hit, the breakdown, the visitors when the fingerprint of the
+// visitor is given, and the hit series when the time of the hit is given. model.ErrHitLimitReached is returned without
+// incrementing when the limit is already reached.
+func (i *InMemoryDB) Hit(key string, hit model.Hit) error {
 	i.mutex.Lock()
 	defer i.mutex.Unlock()
 	value, ok := i.data[key]
 	if !ok {
-		return errors.New(key + " key not exists")
+		return fmt.Errorf("%s key %w", key, model.ErrLinkNotFound)
+	}
+	if value.MaxHits > 0 && value.Hits >= value.MaxHits {
+		return model.ErrHitLimitReached
 	}
 	value.Hits++
+	if hit.Bot {
+		value.BotHits++
+	}
+	if hit.Variant != "" {
+		if value.VariantHits == nil {
+			value.VariantHits = &model.HitCounts{}
+		}
+		value.VariantHits.Add(hit.Variant)
+	}
+	if !hit.Time.IsZero() {
+		if value.Series == nil {
+			value.Series = &model.HitSeries{}
+		}
+		value.Series.Add(hit.Time, hit.Visitor)
+	}
+	if hit.Visitor != 0 {
+		value.Visitors = hyperloglog.Add(value.Visitors, hit.Visitor)
+	}
+	if value.Breakdown == nil {
+		value.Breakdown = &model.HitBreakdown{}
+	}
+	value.Breakdown.Add(hit)
 	i.data[key] = value
 	return nil
 }
 
-// Data returns the in-memory DB data
+// Update atomically applies the given update function to the model.RedirectionData with the given key.
+// The data is not changed when the update function returns an error.
+func (i *InMemoryDB) Update(key string, update func(*model.RedirectionData) error) error {
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
+	value, ok := i.data[key]
+	if !ok {
+		return fmt.Errorf("%s key %w", key, model.ErrLinkNotFound)
+	}
+	if err := update(&value); err != nil {
+		return err
+	}
+	i.data[key] = value
+	return nil
+}
+
+// Data returns a copy of the in-memory DB data, so that it can be iterated while the links are hit and set.
+// The hit counters of the links are shared with the DB and must be read with their methods.
 func (i *InMemoryDB) Data() map[string]model.RedirectionData {
 	i.mutex.RLock()
 	defer i.mutex.RUnlock()
-	return i.data
+	data := make(map[string]model.RedirectionData, len(i.data))
+	for key, value := range i.data {
+		data[key] = value
+	}
+	return data
 }
 
 // Restore restores the in-memory DB data from the given data
 func (i *InMemoryDB) Restore(data map[string]model.RedirectionData) {
-	i.mutex.RLock()
-	defer i.mutex.RUnlock()
+	i.mutex.Lock()
+	defer i.mutex.Unlock()
 	i.data = data
 }
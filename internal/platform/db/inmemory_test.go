@@ -2,7 +2,14 @@ package db
 
 import (
 	"dh-url-shortener/internal/api/model"
+	"dh-url-shortener/internal/platform/hyperloglog"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -52,10 +59,38 @@ func TestInMemoryDB_Data(t *testing.T) {
 	assert.Equal(t, inMemoryDB.data, inMemoryDB.Data())
 }
 
+// TestInMemoryDB_Data should return a copy which can be iterated while the links are hit and set.
+func TestInMemoryDB_Data_ShouldReturnCopy(t *testing.T) {
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key-1"] = model.RedirectionData{OriginalURL: "value-1"}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := 0; n < 100; n++ {
+			for key, value := range inMemoryDB.Data() {
+				_, _ = key, value.Hits
+			}
+		}
+	}()
+	for n := 0; n < 100; n++ {
+		assert.Nil(t, inMemoryDB.Hit("key-1", model.Hit{}))
+		assert.Nil(t, inMemoryDB.Set(fmt.Sprintf("key-%d", n+2), model.RedirectionData{}))
+	}
+	wg.Wait()
+
+	data := inMemoryDB.Data()
+	delete(data, "key-1")
+	value, err := inMemoryDB.Get("key-1")
+	assert.Nil(t, err)
+	assert.Equal(t, 100, value.Hits)
+}
+
 // TestInMemoryRepository_Hit should return error if the key not exists.
 func TestInMemoryRepository_Hit_ShouldReturnErrorWhenKeyNotExists(t *testing.T) {
 	inMemoryDB := NewInMemoryDB()
-	err := inMemoryDB.Hit("key")
+	err := inMemoryDB.Hit("key", model.Hit{})
 
 	assert.Error(t, err)
 }
@@ -64,8 +99,195 @@ func TestInMemoryRepository_Hit_ShouldReturnErrorWhenKeyNotExists(t *testing.T)
 func TestInMemoryRepository_Hit_ShouldIncreaseHitOfRedirectionData(t *testing.T) {
 	inMemoryDB := NewInMemoryDB()
 	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1", Hits: 0}
-	err := inMemoryDB.Hit("key")
+	err := inMemoryDB.Hit("key", model.Hit{})
 
 	assert.Nil(t, err)
 	assert.Equal(t, 1, inMemoryDB.data["key"].Hits)
 }
+
+// TestInMemoryRepository_Hit should count the hits of the bots in the hits and separately.
+func TestInMemoryRepository_Hit_ShouldIncreaseBotHits(t *testing.T) {
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1"}
+
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Bot: true}))
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{}))
+
+	assert.Equal(t, 2, inMemoryDB.data["key"].Hits)
+	assert.Equal(t, 1, inMemoryDB.data["key"].BotHits)
+}
+
+// TestInMemoryRepository_Hit should count the hits of the variants separately.
+func TestInMemoryRepository_Hit_ShouldIncreaseHitOfVariant(t *testing.T) {
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1", VariantHits: model.NewHitCounts(map[string]int{"a": 3})}
+
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Variant: "a"}))
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Variant: "b"}))
+
+	assert.Equal(t, 2, inMemoryDB.data["key"].Hits)
+	assert.Equal(t, 4, inMemoryDB.data["key"].VariantHits.Get("a"))
+	assert.Equal(t, 1, inMemoryDB.data["key"].VariantHits.Get("b"))
+}
+
+// TestInMemoryRepository_Hit should add the hits to the buckets of the series and drop the expired buckets.
+func TestInMemoryRepository_Hit_ShouldAddHitToSeries(t *testing.T) {
+	now := time.Date(2022, 6, 2, 9, 30, 15, 0, time.UTC)
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1"}
+
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Time: now.Add(-25 * time.Hour)}))
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Time: now.Add(-time.Minute)}))
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Time: now}))
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Time: now.Add(10 * time.Second)}))
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{}))
+
+	minute := now.Truncate(time.Minute)
+	hour := now.Truncate(time.Hour)
+	day := time.Date(2022, 6, 2, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, 5, inMemoryDB.data["key"].Hits)
+	assert.Equal(t, &model.HitSeries{
+		Minutes: map[int64]int{minute.Add(-time.Minute).Unix(): 1, minute.Unix(): 2},
+		Hours:   map[int64]int{hour.Add(-25 * time.Hour).Unix(): 1, hour.Unix(): 3},
+		Days:    map[int64]int{day.Add(-24 * time.Hour).Unix(): 1, day.Unix(): 3},
+	}, inMemoryDB.data["key"].Series)
+}
+
+// TestInMemoryRepository_Hit should let the series, the breakdown and the variant hits be read while the link is hit.
+func TestInMemoryRepository_Hit_ShouldAddHitWhileLinkIsRead(t *testing.T) {
+	now := time.Date(2022, 6, 2, 9, 30, 15, 0, time.UTC)
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1"}
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Time: now, Variant: "a"}))
+	data, _ := inMemoryDB.Get("key")
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for n := 0; n < 100; n++ {
+			_, _ = json.Marshal(inMemoryDB.Data())
+			_, _ = data.Series.Range(model.GranularityMinute, now.Add(-time.Hour), now)
+			_ = data.Breakdown.Breakdown()
+			_ = data.VariantHits.Get("a")
+		}
+	}()
+	for n := 1; n < 100; n++ {
+		assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Time: now.Add(time.Duration(n) * time.Minute), ReferrerHost: fmt.Sprintf("r%d.com", n), Visitor: uint64(n) << 32, Variant: "a"}))
+	}
+	wg.Wait()
+
+	hits, _ := data.Series.Range(model.GranularityDay, now.Truncate(24*time.Hour), now.Truncate(24*time.Hour))
+	assert.Equal(t, []int{100}, hits)
+	assert.Len(t, data.Breakdown.Breakdown().Referrers, 50)
+	assert.Equal(t, 100, data.VariantHits.Get("a"))
+
+	var restored model.RedirectionData
+	snapshot, _ := json.Marshal(data)
+	assert.Nil(t, json.Unmarshal(snapshot, &restored))
+	assert.Equal(t, 100, restored.VariantHits.Get("a"))
+}
+
+// TestInMemoryRepository_Hit should keep the referrers with the most hits when the breakdown is full.
+func TestInMemoryRepository_Hit_ShouldCapBreakdown(t *testing.T) {
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1"}
+	agent := model.UserAgent{OS: model.OSIOS, Device: model.DeviceMobile, Browser: model.BrowserSafari}
+	for n := 0; n < 50; n++ {
+		assert.Nil(t, inMemoryDB.Hit("key", model.Hit{ReferrerHost: fmt.Sprintf("r%d.com", n), UserAgent: agent}))
+	}
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{ReferrerHost: "r0.com", UserAgent: agent}))
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{}))
+
+	breakdown := inMemoryDB.data["key"].Breakdown
+	assert.Len(t, breakdown.Referrers, 50)
+	assert.Equal(t, 2, breakdown.Referrers["r0.com"])
+	assert.Equal(t, 2, breakdown.Referrers[model.ReferrerDirect])
+	assert.NotContains(t, breakdown.Referrers, "r1.com")
+	assert.Equal(t, map[string]int{model.BrowserSafari: 51}, breakdown.Browsers)
+	assert.Equal(t, map[string]int{model.OSIOS: 51}, breakdown.OS)
+	assert.Equal(t, map[string]int{model.DeviceMobile: 51}, breakdown.Devices)
+}
+
+// TestInMemoryRepository_Hit should add the visitors of the hits to the sketches of the link and its buckets.
+func TestInMemoryRepository_Hit_ShouldAddVisitor(t *testing.T) {
+	now := time.Date(2022, 6, 2, 9, 30, 15, 0, time.UTC)
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1"}
+
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Time: now, Visitor: 1 << 40}))
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Time: now, Visitor: 1 << 40}))
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Time: now.Add(time.Hour), Visitor: 1 << 60}))
+	assert.Nil(t, inMemoryDB.Hit("key", model.Hit{Time: now}))
+
+	data := inMemoryDB.data["key"]
+	assert.Equal(t, 4, data.Hits)
+	assert.Equal(t, 2, hyperloglog.Estimate(data.Visitors))
+	assert.Equal(t, 1, hyperloglog.Estimate(data.Series.HourVisitors[now.Truncate(time.Hour).Unix()]))
+	assert.Equal(t, 1, hyperloglog.Estimate(data.Series.HourVisitors[now.Truncate(time.Hour).Add(time.Hour).Unix()]))
+	assert.Equal(t, 2, hyperloglog.Estimate(data.Series.DayVisitors[now.Truncate(24*time.Hour).Unix()]))
+	assert.Len(t, data.Series.MinuteVisitors, 2)
+}
+
+// TestInMemoryRepository_Hit should return error when the hit limit is already reached.
+func TestInMemoryRepository_Hit_ShouldReturnErrorWhenHitLimitReached(t *testing.T) {
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1", Hits: 2, LinkOptions: model.LinkOptions{MaxHits: 2}}
+	err := inMemoryDB.Hit("key", model.Hit{})
+
+	assert.ErrorIs(t, err, model.ErrHitLimitReached)
+	assert.Equal(t, 2, inMemoryDB.data["key"].Hits)
+}
+
+// TestInMemoryRepository_Hit should never exceed the hit limit under concurrent hits.
+func TestInMemoryRepository_Hit_ShouldNotOvershootHitLimitWhenHitConcurrently(t *testing.T) {
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1", LinkOptions: model.LinkOptions{MaxHits: 10}}
+
+	var wg sync.WaitGroup
+	var succeeded int32
+	for n := 0; n < 100; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if inMemoryDB.Hit("key", model.Hit{}) == nil {
+				atomic.AddInt32(&succeeded, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(10), succeeded)
+	assert.Equal(t, 10, inMemoryDB.data["key"].Hits)
+}
+
+func TestInMemoryDB_Update(t *testing.T) {
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1"}
+	err := inMemoryDB.Update("key", func(data *model.RedirectionData) error {
+		data.OriginalURL = "value2"
+		return nil
+	})
+
+	assert.Nil(t, err)
+	assert.Equal(t, "value2", inMemoryDB.data["key"].OriginalURL)
+}
+
+func TestInMemoryDB_Update_ShouldNotChangeDataWhenUpdateReturnsError(t *testing.T) {
+	inMemoryDB := NewInMemoryDB()
+	inMemoryDB.data["key"] = model.RedirectionData{OriginalURL: "value1"}
+	err := inMemoryDB.Update("key", func(data *model.RedirectionData) error {
+		data.OriginalURL = "value2"
+		return errors.New("update error")
+	})
+
+	assert.Error(t, err)
+	assert.Equal(t, "value1", inMemoryDB.data["key"].OriginalURL)
+}
+
+func TestInMemoryDB_Update_ShouldReturnErrorWhenKeyNotExists(t *testing.T) {
+	inMemoryDB := NewInMemoryDB()
+	err := inMemoryDB.Update("key", func(data *model.RedirectionData) error { return nil })
+
+	assert.Error(t, err)
+}
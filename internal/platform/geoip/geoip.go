@@ -0,0 +1,152 @@
+package geoip
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"sort"
+	"strings"
+)
+
+// unknownCountry is the country of the ranges which are not located in a country in the IP2Location files.
+const unknownCountry = "-"
+
+// ipRange is a range of IP addresses located in a country. The addresses are in their 16-byte form.
+type ipRange struct {
+	start   net.IP
+	end     net.IP
+	country string
+}
+
+// DB is an offline IP to country lookup table, the ranges in it are not expected to overlap.
+type DB struct {
+	ranges []ipRange
+}
+
+// Open loads the IP ranges from the CSV file at the given path.
+func Open(path string) (*DB, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse parses the IP ranges from the given CSV reader. Each row is either start_ip,end_ip,country like the
+// DB-IP lite and IP2Location lite files, or network,country where the network is a CIDR. The addresses of a range are
+// either dotted or decimal integers like in the IP2Location files, where the IPv4 addresses are below 2^32 and the
+// other ones are IPv6. Empty rows, rows starting with #, a header row and the rows of the ranges without a country,
+// which IP2Location marks with -, are ignored.
+func Parse(r io.Reader) (*DB, error) {
+	reader := csv.NewReader(r)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	db := &DB{}
+	for header := true; ; header = false {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(record) > 2 && strings.TrimSpace(record[2]) == unknownCountry {
+			continue
+		}
+		ipRange, err := parseRecord(record)
+		if err != nil {
+			if header {
+				continue
+			}
+			line, _ := reader.FieldPos(0)
+			return nil, fmt.Errorf("geoip line %d: %w", line, err)
+		}
+		db.ranges = append(db.ranges, ipRange)
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return bytes.Compare(db.ranges[i].start, db.ranges[j].start) < 0
+	})
+	return db, nil
+}
+
+// parseRecord parses an IP range from a row of the CSV file.
+func parseRecord(record []string) (ipRange, error) {
+	switch {
+	case len(record) == 2:
+		_, network, err := net.ParseCIDR(record[0])
+		if err != nil {
+			return ipRange{}, err
+		}
+		return newRange(network.IP, lastIP(network), record[1])
+	case len(record) > 2:
+		start, end := parseIP(record[0]), parseIP(record[1])
+		if start == nil || end == nil {
+			return ipRange{}, fmt.Errorf("invalid ip range %s-%s", record[0], record[1])
+		}
+		return newRange(start, end, record[2])
+	}
+	return ipRange{}, fmt.Errorf("expected ip range and country")
+}
+
+// parseIP parses a dotted IPv4, an IPv6 or a decimal integer address, it returns nil when the address is invalid.
+func parseIP(s string) net.IP {
+	if ip := net.ParseIP(s); ip != nil {
+		return ip
+	}
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 8*net.IPv6len {
+		return nil
+	}
+	if n.BitLen() <= 8*net.IPv4len {
+		return n.FillBytes(make(net.IP, net.IPv4len))
+	}
+	return n.FillBytes(make(net.IP, net.IPv6len))
+}
+
+// newRange creates an IP range with the 16-byte forms of the addresses and the uppercase country code.
+func newRange(start, end net.IP, country string) (ipRange, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if len(country) != 2 {
+		return ipRange{}, fmt.Errorf("invalid country code %q", country)
+	}
+	start, end = start.To16(), end.To16()
+	if bytes.Compare(start, end) > 0 {
+		return ipRange{}, fmt.Errorf("ip range %s-%s ends before it starts", start, end)
+	}
+	return ipRange{start: start, end: end, country: country}, nil
+}
+
+// lastIP returns the last address of the network.
+func lastIP(network *net.IPNet) net.IP {
+	ip := make(net.IP, len(network.IP))
+	for i := range network.IP {
+		ip[i] = network.IP[i] | ^network.Mask[i]
+	}
+	return ip
+}
+
+// Country returns the ISO 3166-1 alpha-2 code of the country of the given IP, or empty if it is unknown.
+func (db *DB) Country(ip net.IP) string {
+	ip = ip.To16()
+	if ip == nil {
+		return ""
+	}
+
+	// the range which may contain the IP is the last one which starts before or at it
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return bytes.Compare(db.ranges[i].start, ip) > 0
+	}) - 1
+	if i < 0 || bytes.Compare(ip, db.ranges[i].end) > 0 {
+		return ""
+	}
+	return db.ranges[i].country
+}
@@ -0,0 +1,100 @@
+package geoip
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRanges = `start_ip,end_ip,country
+# sample of a DB-IP lite file
+1.0.0.0,1.0.0.255,AU
+78.160.0.0,78.191.255.255,tr
+2a02:ff0::,2a02:ff0:ffff:ffff:ffff:ffff:ffff:ffff,TR
+85.214.0.0/15,DE
+`
+
+func TestDB_Country(t *testing.T) {
+	db, err := Parse(strings.NewReader(testRanges))
+	assert.Nil(t, err)
+
+	tests := map[string]string{
+		"1.0.0.0":         "AU",
+		"1.0.0.255":       "AU",
+		"1.0.1.0":         "",
+		"78.170.1.1":      "TR",
+		"85.214.132.117":  "DE",
+		"85.215.255.255":  "DE",
+		"85.216.0.0":      "",
+		"2a02:ff0:1::1":   "TR",
+		"2a02:ff1::1":     "",
+		"0.0.0.1":         "",
+		"255.255.255.255": "",
+	}
+	for ip, expected := range tests {
+		assert.Equal(t, expected, db.Country(net.ParseIP(ip)), ip)
+	}
+	assert.Equal(t, "", db.Country(nil))
+}
+
+// testIP2LocationRanges is a sample of the IP2Location lite IPv4 and IPv6 files, where the addresses are decimal integers
+const testIP2LocationRanges = `"0","16777215","-","-"
+"16777216","16777471","US","United States of America"
+"16777472","16778239","CN","China"
+"1319108608","1321205759","TR","Turkey"
+"281470681743360","281470698520575","-","-"
+"281470698520576","281470698520831","US","United States of America"
+"55838283667586594515714339183750283264","55838283746814757029978676777294233599","TR","Turkey"
+`
+
+func TestDB_Country_ShouldParseIP2LocationRanges(t *testing.T) {
+	db, err := Parse(strings.NewReader(testIP2LocationRanges))
+	assert.Nil(t, err)
+
+	tests := map[string]string{
+		"0.0.0.1":         "",
+		"1.0.0.0":         "US",
+		"1.0.0.255":       "US",
+		"1.0.2.1":         "CN",
+		"78.170.1.1":      "TR",
+		"78.192.0.0":      "",
+		"2a02:ff0:1::1":   "TR",
+		"2a02:ff1::1":     "",
+		"::ffff:1.0.0.42": "US",
+	}
+	for ip, expected := range tests {
+		assert.Equal(t, expected, db.Country(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestParse_ShouldReturnErrorWhenRowIsInvalid(t *testing.T) {
+	tests := map[string]string{
+		"invalid ip":           "1.0.0.0,1.0.0.255,AU\n1.0.0,1.0.1.255,AU",
+		"invalid country":      "1.0.0.0,1.0.0.255,AU\n1.0.1.0,1.0.1.255,Australia",
+		"range ends too early": "1.0.0.0,1.0.0.255,AU\n1.0.1.255,1.0.1.0,AU",
+		"missing country":      "1.0.0.0,1.0.0.255,AU\n1.0.1.0/24",
+		"negative ip":          "1.0.0.0,1.0.0.255,AU\n-1,16777471,AU",
+		"too large ip":         "1.0.0.0,1.0.0.255,AU\n0,340282366920938463463374607431768211456,AU",
+	}
+	for name, ranges := range tests {
+		_, err := Parse(strings.NewReader(ranges))
+		assert.Error(t, err, name)
+	}
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "geoip.csv")
+	assert.Nil(t, os.WriteFile(path, []byte(testRanges), os.ModePerm))
+
+	db, err := Open(path)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "AU", db.Country(net.ParseIP("1.0.0.1")))
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing.csv"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,152 @@
+package hyperloglog
+
+import (
+	"encoding/binary"
+	"math"
+	"math/bits"
+	"sort"
+)
+
+const (
+	// precision is the number of the hash bits which pick the register, the standard error of the estimates is 1.04/sqrt(2^precision).
+	precision = 10
+	registers = 1 << precision
+	// maxSparseEntries is the number of the registers a sparse sketch holds before it becomes dense,
+	// the sparse sketches are always shorter than the dense ones.
+	maxSparseEntries = registers/2 - 1
+)
+
+// Add returns a copy of the sketch with the given hash, the empty sketch is the sketch of no hashes. The sketch is copied
+// instead of being changed in place so that it can be shared by the readers.
+//
+// A sketch is either dense, one byte for the rank of each register, or sparse, two bytes for each non-zero register
+// in the order of the registers. The sparse sketches are used until they would be as long as the dense ones, so the
+// sketches of a few visitors stay small.
+func Add(sketch []byte, hash uint64) []byte {
+	index := uint16(hash >> (64 - precision))
+	rank := uint8(bits.LeadingZeros64(hash<<precision|1<<(precision-1)) + 1)
+
+	if len(sketch) == registers {
+		if sketch[index] >= rank {
+			return sketch
+		}
+		added := make([]byte, registers)
+		copy(added, sketch)
+		added[index] = rank
+		return added
+	}
+
+	entries := sparseEntries(sketch)
+	i := sort.Search(len(entries), func(i int) bool { return entries[i].index >= index })
+	switch {
+	case i < len(entries) && entries[i].index == index && entries[i].rank >= rank:
+		return sketch
+	case i < len(entries) && entries[i].index == index:
+		entries[i].rank = rank
+	default:
+		entries = append(entries, entry{})
+		copy(entries[i+1:], entries[i:])
+		entries[i] = entry{index: index, rank: rank}
+	}
+	if len(entries) > maxSparseEntries {
+		return dense(entries)
+	}
+	return sparse(entries)
+}
+
+// Merge returns the sketch of the hashes of both sketches.
+func Merge(a, b []byte) []byte {
+	if len(a) == 0 {
+		return b
+	}
+	if len(b) == 0 {
+		return a
+	}
+
+	merged := toDense(a)
+	for index, rank := range toDense(b) {
+		if rank > merged[index] {
+			merged[index] = rank
+		}
+	}
+	if len(a) != registers && len(b) != registers {
+		var entries []entry
+		for index, rank := range merged {
+			if rank > 0 {
+				entries = append(entries, entry{index: uint16(index), rank: rank})
+			}
+		}
+		if len(entries) <= maxSparseEntries {
+			return sparse(entries)
+		}
+	}
+	return merged
+}
+
+// Estimate returns the estimated number of the distinct hashes in the sketch.
+func Estimate(sketch []byte) int {
+	if len(sketch) == 0 {
+		return 0
+	}
+
+	var sum float64
+	zeros := 0
+	for _, rank := range toDense(sketch) {
+		sum += math.Ldexp(1, -int(rank))
+		if rank == 0 {
+			zeros++
+		}
+	}
+
+	m := float64(registers)
+	estimate := 0.7213 / (1 + 1.079/m) * m * m / sum
+	// linear counting is more accurate for the small cardinalities
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return int(math.Round(estimate))
+}
+
+// entry is a non-zero register of a sparse sketch.
+type entry struct {
+	index uint16
+	rank  uint8
+}
+
+// sparseEntries decodes the entries of a sparse sketch to a new slice.
+func sparseEntries(sketch []byte) []entry {
+	entries := make([]entry, 0, len(sketch)/2+1)
+	for i := 0; i+1 < len(sketch); i += 2 {
+		packed := binary.BigEndian.Uint16(sketch[i:])
+		entries = append(entries, entry{index: packed >> 6, rank: uint8(packed & 0x3f)})
+	}
+	return entries
+}
+
+// sparse encodes the entries to a sparse sketch, the index takes the high 10 bits and the rank the low 6 bits of each entry.
+func sparse(entries []entry) []byte {
+	sketch := make([]byte, 2*len(entries))
+	for i, e := range entries {
+		binary.BigEndian.PutUint16(sketch[2*i:], e.index<<6|uint16(e.rank))
+	}
+	return sketch
+}
+
+// dense encodes the entries to a dense sketch.
+func dense(entries []entry) []byte {
+	sketch := make([]byte, registers)
+	for _, e := range entries {
+		sketch[e.index] = e.rank
+	}
+	return sketch
+}
+
+// toDense returns a dense copy of the sketch.
+func toDense(sketch []byte) []byte {
+	if len(sketch) == registers {
+		copied := make([]byte, registers)
+		copy(copied, sketch)
+		return copied
+	}
+	return dense(sparseEntries(sketch))
+}
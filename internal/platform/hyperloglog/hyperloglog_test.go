@@ -0,0 +1,95 @@
+package hyperloglog
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// hash is the splitmix64 hash of n, it stands in for the visitor fingerprints.
+func hash(n int) uint64 {
+	z := uint64(n) + 0x9e3779b97f4a7c15
+	z = (z ^ z>>30) * 0xbf58476d1ce4e5b9
+	z = (z ^ z>>27) * 0x94d049bb133111eb
+	return z ^ z>>31
+}
+
+func sketchOf(from, to int) []byte {
+	var sketch []byte
+	for n := from; n < to; n++ {
+		sketch = Add(sketch, hash(n))
+	}
+	return sketch
+}
+
+func TestEstimate(t *testing.T) {
+	for _, cardinality := range []int{0, 1, 10, 100, 1000, 10000, 100000} {
+		sketch := sketchOf(0, cardinality)
+		// twice the standard error of the precision
+		assert.InDelta(t, cardinality, Estimate(sketch), math.Max(1, 0.065*float64(cardinality)), cardinality)
+	}
+}
+
+func TestAdd_ShouldNotCountTheSameHashTwice(t *testing.T) {
+	sketch := sketchOf(0, 10)
+	for n := 0; n < 10; n++ {
+		sketch = Add(sketch, hash(n))
+	}
+	assert.Equal(t, 10, Estimate(sketch))
+}
+
+func TestAdd_ShouldStaySparseForFewHashes(t *testing.T) {
+	assert.Len(t, sketchOf(0, 3), 6)
+	assert.Len(t, sketchOf(0, 10000), registers)
+
+	var sketch []byte
+	for n := 0; len(sketch) < registers; n++ {
+		assert.Less(t, len(sketch), registers-1)
+		sketch = Add(sketch, hash(n))
+	}
+}
+
+func TestAdd_ShouldNotChangeGivenSketch(t *testing.T) {
+	for _, cardinality := range []int{5, 5000} {
+		sketch := sketchOf(0, cardinality)
+		copied := append([]byte(nil), sketch...)
+		for n := cardinality; n < cardinality+100; n++ {
+			_ = Add(sketch, hash(n))
+		}
+		assert.Equal(t, copied, sketch)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		a, b [2]int
+	}{
+		{a: [2]int{0, 10}, b: [2]int{5, 20}},
+		{a: [2]int{0, 100}, b: [2]int{0, 0}},
+		{a: [2]int{0, 5000}, b: [2]int{3000, 8000}},
+		{a: [2]int{0, 300}, b: [2]int{300, 600}},
+	}
+
+	for _, tt := range tests {
+		a, b := sketchOf(tt.a[0], tt.a[1]), sketchOf(tt.b[0], tt.b[1])
+		union := sketchOf(tt.a[0], tt.a[1])
+		for n := tt.b[0]; n < tt.b[1]; n++ {
+			union = Add(union, hash(n))
+		}
+
+		assert.Equal(t, toDense(union), toDense(Merge(a, b)))
+		assert.Equal(t, toDense(union), toDense(Merge(b, a)))
+		assert.Equal(t, Estimate(union), Estimate(Merge(a, b)))
+	}
+}
+
+func TestMerge_ShouldNotChangeGivenSketches(t *testing.T) {
+	a, b := sketchOf(0, 3000), sketchOf(2000, 6000)
+	copiedA, copiedB := append([]byte(nil), a...), append([]byte(nil), b...)
+
+	_ = Merge(a, b)
+
+	assert.Equal(t, copiedA, a)
+	assert.Equal(t, copiedB, b)
+}
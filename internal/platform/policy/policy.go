@@ -0,0 +1,198 @@
+package policy
+
+import (
+	"bufio"
+	"dh-url-shortener/internal/api/model"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	actionAllow = "allow"
+	actionBlock = "block"
+
+	// ruleNotAllowed is reported when allow rules exist and none of them matches the host.
+	ruleNotAllowed = "not in allowlist"
+)
+
+// Rule is a single allow or block rule of the policy.
+// Pattern is either an exact host (example.com), a wildcard subdomain (*.example.com) or a CIDR (10.0.0.0/8).
+type Rule struct {
+	Action  string
+	Pattern string
+	network *net.IPNet
+}
+
+// String returns the rule in the same form it is written in the policy file.
+func (r Rule) String() string {
+	return r.Action + " " + r.Pattern
+}
+
+// matches reports whether the rule matches the given lowercase host.
+func (r Rule) matches(host string) bool {
+	if r.network != nil {
+		ip := net.ParseIP(host)
+		return ip != nil && r.network.Contains(ip)
+	}
+	if strings.HasPrefix(r.Pattern, "*.") {
+		return strings.HasSuffix(host, r.Pattern[1:])
+	}
+	return host == r.Pattern
+}
+
+// Policy decides whether a destination host may be shortened or expanded.
+// Block rules always win, and when at least one allow rule exists only the hosts matching an allow rule are permitted.
+type Policy struct {
+	Path string
+	// Logger reports the files which could not be reloaded, the standard logger is used when it is nil.
+	Logger  *log.Logger
+	rules   []Rule
+	modTime time.Time
+	mutex   sync.RWMutex
+}
+
+// NewPolicy creates a new policy which loads its rules from the given file path.
+func NewPolicy(path string) *Policy {
+	return &Policy{Path: path}
+}
+
+// Check returns a *model.PolicyViolationError if the given host is not permitted by the policy.
+func (p *Policy) Check(host string) error {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+
+	hasAllowRule := false
+	var allowed bool
+	for _, rule := range p.rules {
+		if rule.Action == actionBlock && rule.matches(host) {
+			return &model.PolicyViolationError{Host: host, Rule: rule.String()}
+		}
+		if rule.Action == actionAllow {
+			hasAllowRule = true
+			allowed = allowed || rule.matches(host)
+		}
+	}
+
+	if hasAllowRule && !allowed {
+		return &model.PolicyViolationError{Host: host, Rule: ruleNotAllowed}
+	}
+	return nil
+}
+
+// Rules returns a copy of the currently loaded rules.
+func (p *Policy) Rules() []Rule {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	rules := make([]Rule, len(p.rules))
+	copy(rules, p.rules)
+	return rules
+}
+
+// Load reads the rules from Path and replaces the current rules with them.
+// The current rules are kept if the file can not be read or contains an invalid rule.
+func (p *Policy) Load() error {
+	file, err := os.Open(p.Path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+
+	rules, err := ParseRules(file)
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	// the modification time is recorded even when the file is invalid, so that it is not reloaded until it changes
+	p.modTime = info.ModTime()
+	if err != nil {
+		return err
+	}
+	p.rules = rules
+	return nil
+}
+
+// ReloadPeriodically reloads the rules within each interval if the policy file has been modified.
+func (p *Policy) ReloadPeriodically(interval time.Duration, stop chan bool) {
+	ticker := time.NewTicker(interval)
+
+	for {
+		select {
+		case <-ticker.C:
+			if !p.modified() {
+				continue
+			}
+			if err := p.Load(); err != nil {
+				p.logger().Println("Policy file could not be reloaded, keeping current rules:", err)
+			}
+		case <-stop:
+			ticker.Stop()
+			return
+		}
+	}
+}
+
+// logger returns the logger of the policy, or the standard logger if it has none.
+func (p *Policy) logger() *log.Logger {
+	if p.Logger == nil {
+		return log.Default()
+	}
+	return p.Logger
+}
+
+// modified reports whether the policy file has been changed since it was last loaded or failed to load.
+func (p *Policy) modified() bool {
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return false
+	}
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return !info.ModTime().Equal(p.modTime)
+}
+
+// ParseRules parses the rules from the given reader. Each line contains an action (allow or block) and a pattern.
+// Empty lines and lines starting with # are ignored.
+func ParseRules(r io.Reader) ([]Rule, error) {
+	var rules []Rule
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("policy line %d: expected action and pattern", lineNumber)
+		}
+
+		rule := Rule{Action: strings.ToLower(fields[0]), Pattern: strings.ToLower(fields[1])}
+		if rule.Action != actionAllow && rule.Action != actionBlock {
+			return nil, fmt.Errorf("policy line %d: unknown action %q", lineNumber, fields[0])
+		}
+		if strings.Contains(rule.Pattern, "/") {
+			_, network, err := net.ParseCIDR(rule.Pattern)
+			if err != nil {
+				return nil, fmt.Errorf("policy line %d: %w", lineNumber, err)
+			}
+			rule.network = network
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, scanner.Err()
+}
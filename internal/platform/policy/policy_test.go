@@ -0,0 +1,137 @@
+package policy
+
+import (
+	"bytes"
+	"dh-url-shortener/internal/api/model"
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testRules = `
+# own and partner domains
+allow yemeksepeti.com
+allow *.partner.com
+allow 10.0.0.0/8
+block evil.partner.com
+block 10.1.0.0/16
+`
+
+func newTestPolicy(t *testing.T, rules string) *Policy {
+	path := filepath.Join(t.TempDir(), "policy.txt")
+	assert.Nil(t, os.WriteFile(path, []byte(rules), os.ModePerm))
+	p := NewPolicy(path)
+	assert.Nil(t, p.Load())
+	return p
+}
+
+func TestPolicy_Check(t *testing.T) {
+	p := newTestPolicy(t, testRules)
+	tests := []struct {
+		host string
+		rule string
+	}{
+		{host: "yemeksepeti.com"},
+		{host: "YemekSepeti.com."},
+		{host: "www.yemeksepeti.com", rule: ruleNotAllowed},
+		{host: "shop.partner.com"},
+		{host: "partner.com", rule: ruleNotAllowed},
+		{host: "evil.partner.com", rule: "block evil.partner.com"},
+		{host: "10.2.3.4"},
+		{host: "10.1.3.4", rule: "block 10.1.0.0/16"},
+		{host: "192.168.1.1", rule: ruleNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.host, func(t *testing.T) {
+			err := p.Check(tt.host)
+			if tt.rule == "" {
+				assert.Nil(t, err)
+				return
+			}
+			var policyErr *model.PolicyViolationError
+			assert.True(t, errors.As(err, &policyErr))
+			assert.Equal(t, tt.rule, policyErr.Rule)
+		})
+	}
+}
+
+func TestPolicy_Check_ShouldAllowEveryHostWhenThereIsNoAllowRule(t *testing.T) {
+	p := newTestPolicy(t, "block evil.com")
+	assert.Nil(t, p.Check("example.com"))
+	assert.Error(t, p.Check("evil.com"))
+}
+
+func TestParseRules_ShouldReturnErrorWhenRuleIsInvalid(t *testing.T) {
+	invalidRules := []string{"deny example.com", "allow", "block 10.0.0.0/33"}
+	for _, rules := range invalidRules {
+		_, err := ParseRules(strings.NewReader(rules))
+		assert.Error(t, err, rules)
+	}
+}
+
+func TestPolicy_Load_ShouldKeepCurrentRulesWhenFileIsInvalid(t *testing.T) {
+	p := newTestPolicy(t, "block evil.com")
+	assert.Nil(t, os.WriteFile(p.Path, []byte("invalid"), os.ModePerm))
+
+	assert.Error(t, p.Load())
+	assert.Equal(t, "block evil.com", p.Rules()[0].String())
+}
+
+func TestPolicy_ReloadPeriodically_ShouldReloadModifiedFile(t *testing.T) {
+	p := newTestPolicy(t, "block evil.com")
+	assert.Nil(t, os.WriteFile(p.Path, []byte("block worse.com"), os.ModePerm))
+	assert.Nil(t, os.Chtimes(p.Path, time.Now(), time.Now().Add(time.Minute)))
+
+	stop := make(chan bool)
+	go p.ReloadPeriodically(10*time.Millisecond, stop)
+	assert.Eventually(t, func() bool { return p.Check("worse.com") != nil }, time.Second, 10*time.Millisecond)
+	stop <- true
+
+	assert.Nil(t, p.Check("evil.com"))
+}
+
+// lockedBuffer is a buffer which can be written by the reload goroutine while the test reads it.
+type lockedBuffer struct {
+	buffer bytes.Buffer
+	mutex  sync.Mutex
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return b.buffer.Write(p)
+}
+
+func (b *lockedBuffer) count(text string) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	return strings.Count(b.buffer.String(), text)
+}
+
+func TestPolicy_ReloadPeriodically_ShouldReportInvalidFileOnce(t *testing.T) {
+	const message = "Policy file could not be reloaded"
+	var output lockedBuffer
+	p := newTestPolicy(t, "block evil.com")
+	p.Logger = log.New(&output, "", 0)
+	assert.Nil(t, os.WriteFile(p.Path, []byte("invalid"), os.ModePerm))
+	assert.Nil(t, os.Chtimes(p.Path, time.Now(), time.Now().Add(time.Minute)))
+
+	stop := make(chan bool)
+	go p.ReloadPeriodically(10*time.Millisecond, stop)
+	assert.Eventually(t, func() bool { return output.count(message) > 0 }, time.Second, 10*time.Millisecond)
+	assert.False(t, p.modified())
+	assert.Nil(t, os.WriteFile(p.Path, []byte("block worse.com"), os.ModePerm))
+	assert.Nil(t, os.Chtimes(p.Path, time.Now(), time.Now().Add(2*time.Minute)))
+	assert.Eventually(t, func() bool { return p.Check("worse.com") != nil }, time.Second, 10*time.Millisecond)
+	stop <- true
+
+	assert.Equal(t, 1, output.count(message))
+}
@@ -0,0 +1,91 @@
+package safehash
+
+import (
+	"encoding/binary"
+	"strings"
+)
+
+// Alphabet is the alphabet of the safe hashes. It excludes the characters which are easily confused when a hash is
+// read from print, 0 and o, 1, i and l, and the uppercase letters so that the hashes can be typed in any case.
+// The check character algorithm needs an alphabet whose length is a prime number.
+const Alphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+// Length is the length of a safe hash, its last character is the check character of the others.
+const Length = 8
+
+// Encode encodes the first bytes of the given digest, which must be at least 8 bytes long, to a safe hash.
+func Encode(digest []byte) string {
+	n := binary.BigEndian.Uint64(digest)
+	hash := make([]byte, Length-1)
+	for i := range hash {
+		hash[i] = Alphabet[n%uint64(len(Alphabet))]
+		n /= uint64(len(Alphabet))
+	}
+	return string(hash) + string(CheckChar(string(hash)))
+}
+
+// CheckChar returns the check character of the given payload. Like the ISBN-10 check digit, the check character makes
+// the sum of the character values weighted by their positions divisible by the length of the Alphabet. Since the
+// length is prime, every single mistyped character and every swap of two adjacent characters changes the sum.
+// The payload must be in the Alphabet.
+func CheckChar(payload string) byte {
+	base := len(Alphabet)
+	sum := weightedSum(payload)
+	for c := 0; c < base; c++ {
+		if (sum+(len(payload)+1)*c)%base == 0 {
+			return Alphabet[c]
+		}
+	}
+	return Alphabet[0]
+}
+
+// weightedSum returns the sum of the values of the characters of the hash weighted by their positions starting from 1.
+func weightedSum(hash string) int {
+	sum := 0
+	for i := 0; i < len(hash); i++ {
+		sum += (i + 1) * strings.IndexByte(Alphabet, hash[i])
+	}
+	return sum
+}
+
+// Valid reports whether the given hash is a safe hash with a correct check character.
+func Valid(hash string) bool {
+	if len(hash) != Length {
+		return false
+	}
+	for i := 0; i < len(hash); i++ {
+		if strings.IndexByte(Alphabet, hash[i]) < 0 {
+			return false
+		}
+	}
+	return weightedSum(hash)%len(Alphabet) == 0
+}
+
+// Candidates returns the hashes which differ from the given hash by one character or by a swap of two adjacent
+// characters and have a correct check character, in the order of the positions of the differences.
+func Candidates(hash string) []string {
+	hash = strings.ToLower(hash)
+	if len(hash) != Length {
+		return nil
+	}
+
+	var candidates []string
+	add := func(candidate []byte) {
+		if Valid(string(candidate)) && string(candidate) != hash {
+			candidates = append(candidates, string(candidate))
+		}
+	}
+	for i := 0; i < Length; i++ {
+		for j := 0; j < len(Alphabet); j++ {
+			candidate := []byte(hash)
+			candidate[i] = Alphabet[j]
+			add(candidate)
+		}
+		if i+1 < Length {
+			candidate := []byte(hash)
+			candidate[i], candidate[i+1] = candidate[i+1], candidate[i]
+			add(candidate)
+		}
+	}
+	return candidates
+}
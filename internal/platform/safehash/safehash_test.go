@@ -0,0 +1,69 @@
+package safehash
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncode(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("https://www.yemeksepeti.com/%d", i)))
+		hash := Encode(sum[:])
+
+		assert.Len(t, hash, Length)
+		assert.True(t, Valid(hash), hash)
+		assert.False(t, strings.ContainsAny(hash, "01ilo"), hash)
+	}
+}
+
+func TestValid_ShouldCatchSingleMistypedCharactersAndAdjacentSwaps(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		sum := sha256.Sum256([]byte(fmt.Sprintf("https://www.yemeksepeti.com/%d", i)))
+		hash := Encode(sum[:])
+
+		for position := 0; position < Length; position++ {
+			for j := 0; j < len(Alphabet); j++ {
+				mistyped := []byte(hash)
+				mistyped[position] = Alphabet[j]
+				assert.Equal(t, string(mistyped) == hash, Valid(string(mistyped)), string(mistyped))
+			}
+			if position+1 < Length && hash[position] != hash[position+1] {
+				swapped := []byte(hash)
+				swapped[position], swapped[position+1] = swapped[position+1], swapped[position]
+				assert.False(t, Valid(string(swapped)), string(swapped))
+			}
+		}
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := map[string]bool{
+		"23456787":  CheckChar("2345678") == '7',
+		"2345678":   false,
+		"234567890": false,
+		"2345678O":  false,
+		"ABCDEFGH":  false,
+	}
+	for hash, expected := range tests {
+		assert.Equal(t, expected, Valid(hash), hash)
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	sum := sha256.Sum256([]byte("https://www.yemeksepeti.com/istanbul"))
+	hash := Encode(sum[:])
+	mistyped := []byte(hash)
+	mistyped[3] = Alphabet[(strings.IndexByte(Alphabet, hash[3])+1)%len(Alphabet)]
+
+	candidates := Candidates(strings.ToUpper(string(mistyped)))
+
+	assert.Contains(t, candidates, hash)
+	for _, candidate := range candidates {
+		assert.True(t, Valid(candidate), candidate)
+	}
+	assert.Nil(t, Candidates("2345678"))
+}
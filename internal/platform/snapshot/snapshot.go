@@ -10,6 +10,36 @@ import (
 	"time"
 )
 
+// file is the content of the snapshot file, the links are partitioned by their workspace so that the links of
+// a single workspace can be exported or deleted. The links of the default workspace are under the empty workspace.
+type file struct {
+	Workspaces map[string]map[string]model.RedirectionData `json:"workspaces"`
+}
+
+// partition groups the links of the database by their workspace.
+func partition(data map[string]model.RedirectionData) file {
+	f := file{Workspaces: make(map[string]map[string]model.RedirectionData)}
+	for key, link := range data {
+		if f.Workspaces[link.Workspace] == nil {
+			f.Workspaces[link.Workspace] = make(map[string]model.RedirectionData)
+		}
+		f.Workspaces[link.Workspace][key] = link
+	}
+	return f
+}
+
+// merge returns the links of all workspaces. The links are assigned to the workspace they are stored under.
+func (f file) merge() map[string]model.RedirectionData {
+	data := make(map[string]model.RedirectionData)
+	for workspace, links := range f.Workspaces {
+		for key, link := range links {
+			link.Workspace = workspace
+			data[key] = link
+		}
+	}
+	return data
+}
+
 // Snapshot saves and restores the state of the database.
 type Snapshot struct {
 	SnapshotPath         string
@@ -26,28 +56,38 @@ func NewSnapshot(snapshotPath string, snapshotSaveInterval time.Duration) *Snaps
 
 // Save saves the current state of the database to SnapshotPath.
 func (s Snapshot) snapshot(db service.DB) error {
-	file, err := os.OpenFile(s.SnapshotPath, os.O_WRONLY|os.O_CREATE, os.ModePerm)
+	snapshotFile, err := os.OpenFile(s.SnapshotPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.ModePerm)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	defer snapshotFile.Close()
 
-	_ = json.NewEncoder(file).Encode(db.Data())
+	_ = json.NewEncoder(snapshotFile).Encode(partition(db.Data()))
 
 	return nil
 }
 
 // Restore restores the state of the database from SnapshotPath.
+// The snapshots which are saved before the workspaces are a single map of the links, they are restored to the default workspace.
 func (s Snapshot) Restore(db service.DB) error {
-	file, err := os.OpenFile(s.SnapshotPath, os.O_RDONLY, os.ModePerm)
+	snapshotFile, err := os.OpenFile(s.SnapshotPath, os.O_RDONLY, os.ModePerm)
 	if err != nil {
 		log.Println("Snapshot file not found, starting from empty database")
 		return nil
 	}
-	defer file.Close()
-	byteValue, _ := io.ReadAll(file)
-	var data map[string]model.RedirectionData
+	defer snapshotFile.Close()
+	byteValue, _ := io.ReadAll(snapshotFile)
 	if len(byteValue) > 0 {
+		var f file
+		if err := json.Unmarshal(byteValue, &f); err != nil {
+			return err
+		}
+		if f.Workspaces != nil {
+			db.Restore(f.merge())
+			return nil
+		}
+
+		var data map[string]model.RedirectionData
 		if err := json.Unmarshal(byteValue, &data); err != nil {
 			return err
 		}
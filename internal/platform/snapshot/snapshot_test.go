@@ -5,13 +5,15 @@ import (
 	"dh-url-shortener/internal/platform/db"
 	"encoding/json"
 	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 )
 
-const testSnapshotFile = "test_snapshot.db"
 const testSnapshotInterval = time.Second * 2
 
 func TestNewSnapshot_Restore_ShouldNotReturnErrorWhenSnapshotFileCantOpen(t *testing.T) {
@@ -26,10 +28,10 @@ func TestNewSnapshot_Restore_ShouldNotReturnErrorWhenSnapshotFileCantOpen(t *tes
 
 func TestNewSnapshot_Restore_ShouldReturnErrorWhenFileContentIsNotEncodeable(t *testing.T) {
 	inMemDB := db.NewInMemoryDB()
+	testSnapshotFile := testSnapshotPath(t)
 	snapshot := NewSnapshot(testSnapshotFile, testSnapshotInterval)
 
 	writeDataToSnapshot(t, []byte("not encodeable"), testSnapshotFile)
-	defer os.Truncate(testSnapshotFile, 0)
 	err := snapshot.Restore(inMemDB)
 	expectedData := map[string]model.RedirectionData{}
 	assert.Error(t, err)
@@ -38,6 +40,7 @@ func TestNewSnapshot_Restore_ShouldReturnErrorWhenFileContentIsNotEncodeable(t *
 
 func TestSnapshot_Restore(t *testing.T) {
 	inMemDB := db.NewInMemoryDB()
+	testSnapshotFile := testSnapshotPath(t)
 	snapshot := NewSnapshot(testSnapshotFile, testSnapshotInterval)
 	testData := map[string]model.RedirectionData{
 		"key1": {OriginalURL: "value1"},
@@ -46,7 +49,6 @@ func TestSnapshot_Restore(t *testing.T) {
 	}
 	d, _ := json.Marshal(testData)
 	writeDataToSnapshot(t, d, testSnapshotFile)
-	defer os.Truncate(testSnapshotFile, 0)
 	err := snapshot.Restore(inMemDB)
 	assert.Nil(t, err)
 	assert.Equal(t, testData, inMemDB.Data())
@@ -55,6 +57,7 @@ func TestSnapshot_Restore(t *testing.T) {
 func TestSnapshot_SavePeriodically(t *testing.T) {
 	inMemDB := db.NewInMemoryDB()
 	inMemDB2 := db.NewInMemoryDB()
+	testSnapshotFile := testSnapshotPath(t)
 	snapshot := NewSnapshot(testSnapshotFile, testSnapshotInterval)
 	testData := map[string]model.RedirectionData{
 		"key1": {OriginalURL: "value1"},
@@ -68,12 +71,66 @@ func TestSnapshot_SavePeriodically(t *testing.T) {
 	})
 	snapshot.SavePeriodically(inMemDB, stopTimerCh)
 
-	defer os.Remove(testSnapshotFile)
 	inMemDB2.Restore(testData)
 	assert.Equal(t, testData, inMemDB2.Data())
 	assert.FileExists(t, testSnapshotFile)
 }
 
+func TestSnapshot_ShouldKeepRevisionHistoryAndMetadata(t *testing.T) {
+	inMemDB := db.NewInMemoryDB()
+	inMemDB2 := db.NewInMemoryDB()
+	testSnapshotFile := testSnapshotPath(t)
+	snapshot := NewSnapshot(testSnapshotFile, testSnapshotInterval)
+	testData := map[string]model.RedirectionData{
+		"key1": {OriginalURL: "value2", Revisions: []model.Revision{
+			{Revision: 1, Time: time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC), OldURL: "value1", NewURL: "value2", Actor: "tuncay"},
+		}, LinkOptions: model.LinkOptions{Metadata: model.Metadata{Title: "title", Tags: []string{"food"}, Notes: "notes"}}},
+	}
+	inMemDB.Restore(testData)
+
+	assert.Nil(t, snapshot.snapshot(inMemDB))
+	assert.Nil(t, snapshot.Restore(inMemDB2))
+	assert.Equal(t, testData, inMemDB2.Data())
+}
+
+func TestSnapshot_snapshot_ShouldPartitionLinksByWorkspace(t *testing.T) {
+	inMemDB := db.NewInMemoryDB()
+	inMemDB2 := db.NewInMemoryDB()
+	testSnapshotFile := testSnapshotPath(t)
+	snapshot := NewSnapshot(testSnapshotFile, testSnapshotInterval)
+	testData := map[string]model.RedirectionData{
+		"key1": {OriginalURL: "value1"},
+		"key2": {OriginalURL: "value2", LinkOptions: model.LinkOptions{Workspace: "team-a"}},
+		"key3": {OriginalURL: "value3", LinkOptions: model.LinkOptions{Workspace: "team-a"}},
+	}
+	inMemDB.Restore(testData)
+	writeDataToSnapshot(t, []byte(strings.Repeat(" ", 4096)+"garbage"), testSnapshotFile)
+
+	assert.Nil(t, snapshot.snapshot(inMemDB))
+
+	content, err := os.ReadFile(testSnapshotFile)
+	assert.Nil(t, err)
+	var f file
+	assert.Nil(t, json.Unmarshal(content, &f))
+	assert.Equal(t, []string{"key1"}, keys(f.Workspaces[""]))
+	assert.Equal(t, []string{"key2", "key3"}, keys(f.Workspaces["team-a"]))
+
+	assert.Nil(t, snapshot.Restore(inMemDB2))
+	assert.Equal(t, testData, inMemDB2.Data())
+}
+
+func TestSnapshot_Restore_ShouldAssignLinksToTheirWorkspacePartition(t *testing.T) {
+	inMemDB := db.NewInMemoryDB()
+	testSnapshotFile := testSnapshotPath(t)
+	snapshot := NewSnapshot(testSnapshotFile, testSnapshotInterval)
+	writeDataToSnapshot(t, []byte(`{"workspaces":{"team-b":{"key1":{"OriginalURL":"value1"}}}}`), testSnapshotFile)
+
+	assert.Nil(t, snapshot.Restore(inMemDB))
+	assert.Equal(t, map[string]model.RedirectionData{
+		"key1": {OriginalURL: "value1", LinkOptions: model.LinkOptions{Workspace: "team-b"}},
+	}, inMemDB.Data())
+}
+
 func TestSnapshot_snapshot_ShouldReturnErrorWhenFileCanNotOpenForWrite(t *testing.T) {
 	inMemDB := db.NewInMemoryDB()
 	snapshot := NewSnapshot("", testSnapshotInterval)
@@ -81,6 +138,11 @@ func TestSnapshot_snapshot_ShouldReturnErrorWhenFileCanNotOpenForWrite(t *testin
 	assert.Error(t, err)
 }
 
+// testSnapshotPath returns the path of a snapshot file in a temporary directory which is removed after the test.
+func testSnapshotPath(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "test_snapshot.db")
+}
+
 func writeDataToSnapshot(t *testing.T, data []byte, snapshotPath string) {
 	file, err := os.OpenFile(snapshotPath, os.O_WRONLY|os.O_CREATE, os.ModePerm)
 	assert.Nil(t, err)
@@ -89,3 +151,12 @@ func writeDataToSnapshot(t *testing.T, data []byte, snapshotPath string) {
 	_, err = file.Write(data)
 	assert.Nil(t, err)
 }
+
+func keys(links map[string]model.RedirectionData) []string {
+	var keys []string
+	for key := range links {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
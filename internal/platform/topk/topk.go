@@ -0,0 +1,116 @@
+package topk
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Entry is a key with its counted occurrences. Count overestimates the occurrences by at most Error.
+type Entry struct {
+	Key   string
+	Count int
+	Error int
+}
+
+// Sketch finds the most frequent keys of a stream in a fixed memory with the space-saving algorithm. It counts up to
+// Capacity keys, and when it is full, the key with the least count is replaced by the new key which takes over its count.
+// The keys which occur more often than the total count divided by the capacity are always kept. It is not safe for
+// concurrent use.
+type Sketch struct {
+	Capacity int
+	entries  map[string]*item
+	heap     minHeap
+}
+
+// item is an entry in the min-heap of the counts.
+type item struct {
+	Entry
+	index int
+}
+
+// New creates a sketch which counts up to capacity keys.
+func New(capacity int) *Sketch {
+	return &Sketch{Capacity: capacity, entries: make(map[string]*item)}
+}
+
+// Add counts the given occurrences of the key.
+func (s *Sketch) Add(key string, count int) {
+	if it, ok := s.entries[key]; ok {
+		it.Count += count
+		heap.Fix(&s.heap, it.index)
+		return
+	}
+	if len(s.heap) < s.Capacity {
+		it := &item{Entry: Entry{Key: key, Count: count}}
+		s.entries[key] = it
+		heap.Push(&s.heap, it)
+		return
+	}
+	if s.Capacity <= 0 {
+		return
+	}
+
+	least := s.heap[0]
+	delete(s.entries, least.Key)
+	least.Key, least.Error, least.Count = key, least.Count, least.Count+count
+	s.entries[key] = least
+	heap.Fix(&s.heap, 0)
+}
+
+// Entries returns the counted keys in no particular order.
+func (s *Sketch) Entries() []Entry {
+	entries := make([]Entry, 0, len(s.heap))
+	for _, it := range s.heap {
+		entries = append(entries, it.Entry)
+	}
+	return entries
+}
+
+// Top returns the n keys with the largest counts of the given sketches. The counts and the errors of the same key are summed.
+func Top(n int, sketches ...*Sketch) []Entry {
+	merged := make(map[string]Entry)
+	for _, s := range sketches {
+		for _, it := range s.heap {
+			e := merged[it.Key]
+			merged[it.Key] = Entry{Key: it.Key, Count: e.Count + it.Count, Error: e.Error + it.Error}
+		}
+	}
+
+	entries := make([]Entry, 0, len(merged))
+	for _, e := range merged {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Count != entries[j].Count {
+			return entries[i].Count > entries[j].Count
+		}
+		return entries[i].Key < entries[j].Key
+	})
+	if n >= 0 && len(entries) > n {
+		entries = entries[:n]
+	}
+	return entries
+}
+
+// minHeap orders the items by their counts, the item with the least count is the first.
+type minHeap []*item
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].Count < h[j].Count }
+func (h minHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+
+func (h *minHeap) Push(x interface{}) {
+	it := x.(*item)
+	it.index = len(*h)
+	*h = append(*h, it)
+}
+
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	it := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return it
+}
@@ -0,0 +1,65 @@
+package topk
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSketch_Add(t *testing.T) {
+	s := New(3)
+	for _, key := range []string{"a", "b", "a", "c", "a", "b"} {
+		s.Add(key, 1)
+	}
+
+	assert.Equal(t, []Entry{{Key: "a", Count: 3}, {Key: "b", Count: 2}, {Key: "c", Count: 1}}, Top(10, s))
+}
+
+func TestSketch_Add_ShouldReplaceLeastCountWhenFull(t *testing.T) {
+	s := New(2)
+	s.Add("a", 5)
+	s.Add("b", 2)
+	s.Add("c", 1)
+
+	assert.Equal(t, []Entry{{Key: "a", Count: 5}, {Key: "c", Count: 3, Error: 2}}, Top(10, s))
+	assert.Len(t, s.Entries(), 2)
+}
+
+func TestSketch_Add_ShouldKeepFrequentKeys(t *testing.T) {
+	s := New(20)
+	for n := 0; n < 10000; n++ {
+		// every third key is one of the five frequent keys, the others are unique, so the frequent keys occur
+		// more often than the total count divided by the capacity
+		if n%3 == 0 {
+			s.Add(fmt.Sprintf("frequent-%d", n%5), 1)
+		} else {
+			s.Add(fmt.Sprintf("rare-%d", n), 1)
+		}
+	}
+
+	top := Top(5, s)
+	assert.Len(t, top, 5)
+	for _, e := range top {
+		assert.Contains(t, e.Key, "frequent-")
+		assert.GreaterOrEqual(t, e.Count, 666)
+		assert.LessOrEqual(t, e.Count-e.Error, 667)
+	}
+}
+
+func TestSketch_Add_ShouldIgnoreKeysWhenCapacityIsZero(t *testing.T) {
+	s := New(0)
+	s.Add("a", 1)
+	assert.Empty(t, Top(10, s))
+}
+
+func TestTop_ShouldSumCountsOfSketches(t *testing.T) {
+	a, b := New(5), New(5)
+	a.Add("x", 2)
+	a.Add("y", 4)
+	b.Add("x", 3)
+	b.Add("z", 1)
+
+	assert.Equal(t, []Entry{{Key: "x", Count: 5}, {Key: "y", Count: 4}}, Top(2, a, b))
+	assert.Equal(t, []Entry{{Key: "x", Count: 5}, {Key: "y", Count: 4}, {Key: "z", Count: 1}}, Top(-1, a, b))
+}
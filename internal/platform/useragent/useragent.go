@@ -0,0 +1,78 @@
+package useragent
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"strings"
+)
+
+// token is a substring of the User-Agent header which identifies a value.
+type token struct {
+	substring string
+	value     string
+}
+
+// The tokens are checked in order and the first match wins, so the more specific tokens come first.
+// E.g. Edge and Opera also send "Chrome/" and "Safari/", and Android tablets do not send "Mobile".
+var (
+	osTokens = []token{
+		{"iPhone", model.OSIOS},
+		{"iPad", model.OSIOS},
+		{"iPod", model.OSIOS},
+		{"Android", model.OSAndroid},
+		{"CrOS", model.OSChromeOS},
+		{"Windows", model.OSWindows},
+		{"Macintosh", model.OSMacOS},
+		{"Mac OS X", model.OSMacOS},
+		{"Linux", model.OSLinux},
+	}
+	browserTokens = []token{
+		{"Edg/", model.BrowserEdge},
+		{"EdgA/", model.BrowserEdge},
+		{"EdgiOS/", model.BrowserEdge},
+		{"OPR/", model.BrowserOpera},
+		{"Opera", model.BrowserOpera},
+		{"SamsungBrowser/", model.BrowserSamsung},
+		{"Firefox/", model.BrowserFirefox},
+		{"FxiOS/", model.BrowserFirefox},
+		{"Chrome/", model.BrowserChrome},
+		{"CriOS/", model.BrowserChrome},
+		{"Chromium/", model.BrowserChrome},
+		{"Safari/", model.BrowserSafari},
+	}
+)
+
+// Parse parses the OS, the device class and the browser from the given User-Agent header.
+// The values which can not be recognized are model.UserAgentOther.
+func Parse(header string) model.UserAgent {
+	agent := model.UserAgent{
+		OS:      find(header, osTokens),
+		Browser: find(header, browserTokens),
+	}
+	agent.Device = device(header, agent.OS)
+	return agent
+}
+
+// device returns the device class of the User-Agent header with the given OS.
+func device(header, os string) string {
+	switch {
+	case strings.Contains(header, "iPad"), strings.Contains(header, "Tablet"):
+		return model.DeviceTablet
+	case os == model.OSAndroid && !strings.Contains(header, "Mobile"):
+		return model.DeviceTablet
+	case os == model.OSIOS, strings.Contains(header, "Mobi"):
+		return model.DeviceMobile
+	case os == model.UserAgentOther:
+		return model.UserAgentOther
+	}
+	return model.DeviceDesktop
+}
+
+// find returns the value of the first token which the header contains.
+func find(header string, tokens []token) string {
+	for _, t := range tokens {
+		if strings.Contains(header, t.substring) {
+			return t.value
+		}
+	}
+	return model.UserAgentOther
+}
@@ -0,0 +1,88 @@
+package useragent
+
+import (
+	"dh-url-shortener/internal/api/model"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		header   string
+		expected model.UserAgent
+	}{
+		{
+			name:     "safari on iphone",
+			header:   "Mozilla/5.0 (iPhone; CPU iPhone OS 15_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.5 Mobile/15E148 Safari/604.1",
+			expected: model.UserAgent{OS: model.OSIOS, Device: model.DeviceMobile, Browser: model.BrowserSafari},
+		},
+		{
+			name:     "chrome on iphone",
+			header:   "Mozilla/5.0 (iPhone; CPU iPhone OS 15_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/102.0.5005.87 Mobile/15E148 Safari/604.1",
+			expected: model.UserAgent{OS: model.OSIOS, Device: model.DeviceMobile, Browser: model.BrowserChrome},
+		},
+		{
+			name:     "safari on ipad",
+			header:   "Mozilla/5.0 (iPad; CPU OS 15_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.5 Mobile/15E148 Safari/604.1",
+			expected: model.UserAgent{OS: model.OSIOS, Device: model.DeviceTablet, Browser: model.BrowserSafari},
+		},
+		{
+			name:     "chrome on android phone",
+			header:   "Mozilla/5.0 (Linux; Android 12; Pixel 6) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/102.0.5005.78 Mobile Safari/537.36",
+			expected: model.UserAgent{OS: model.OSAndroid, Device: model.DeviceMobile, Browser: model.BrowserChrome},
+		},
+		{
+			name:     "samsung browser on android tablet",
+			header:   "Mozilla/5.0 (Linux; Android 11; SM-T870) AppleWebKit/537.36 (KHTML, like Gecko) SamsungBrowser/17.0 Chrome/96.0.4664.104 Safari/537.36",
+			expected: model.UserAgent{OS: model.OSAndroid, Device: model.DeviceTablet, Browser: model.BrowserSamsung},
+		},
+		{
+			name:     "firefox on android phone",
+			header:   "Mozilla/5.0 (Android 12; Mobile; rv:101.0) Gecko/101.0 Firefox/101.0",
+			expected: model.UserAgent{OS: model.OSAndroid, Device: model.DeviceMobile, Browser: model.BrowserFirefox},
+		},
+		{
+			name:     "edge on windows",
+			header:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/102.0.5005.63 Safari/537.36 Edg/102.0.1245.39",
+			expected: model.UserAgent{OS: model.OSWindows, Device: model.DeviceDesktop, Browser: model.BrowserEdge},
+		},
+		{
+			name:     "opera on windows",
+			header:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/101.0.4951.67 Safari/537.36 OPR/87.0.4390.45",
+			expected: model.UserAgent{OS: model.OSWindows, Device: model.DeviceDesktop, Browser: model.BrowserOpera},
+		},
+		{
+			name:     "safari on mac",
+			header:   "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/15.5 Safari/605.1.15",
+			expected: model.UserAgent{OS: model.OSMacOS, Device: model.DeviceDesktop, Browser: model.BrowserSafari},
+		},
+		{
+			name:     "firefox on linux",
+			header:   "Mozilla/5.0 (X11; Ubuntu; Linux x86_64; rv:101.0) Gecko/20100101 Firefox/101.0",
+			expected: model.UserAgent{OS: model.OSLinux, Device: model.DeviceDesktop, Browser: model.BrowserFirefox},
+		},
+		{
+			name:     "chrome on chromebook",
+			header:   "Mozilla/5.0 (X11; CrOS x86_64 14695.85.0) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/102.0.5005.75 Safari/537.36",
+			expected: model.UserAgent{OS: model.OSChromeOS, Device: model.DeviceDesktop, Browser: model.BrowserChrome},
+		},
+		{
+			name:     "command line client",
+			header:   "curl/7.79.1",
+			expected: model.UserAgent{OS: model.UserAgentOther, Device: model.UserAgentOther, Browser: model.UserAgentOther},
+		},
+		{
+			name:     "empty header",
+			header:   "",
+			expected: model.UserAgent{OS: model.UserAgentOther, Device: model.UserAgentOther, Browser: model.UserAgentOther},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Parse(tt.header))
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package wordfilter
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+)
+
+// leetspeak folds the characters which look alike to the same letter, so the words are matched with their
+// leetspeak variants, e.g. b00b and 8oob. The letter l is folded to i, because 1 is used for both of them.
+var leetspeak = strings.NewReplacer(
+	"0", "o",
+	"1", "i",
+	"l", "i",
+	"!", "i",
+	"3", "e",
+	"4", "a",
+	"@", "a",
+	"5", "s",
+	"$", "s",
+	"7", "t",
+	"8", "b",
+	"9", "g",
+)
+
+// Filter finds the offensive words in short URL hashes.
+type Filter struct {
+	words []string
+}
+
+// Open loads the words from the file at the given path.
+func Open(path string) (*Filter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file)
+}
+
+// Parse parses the words from the given reader, one word per line. Empty lines and lines starting with # are ignored.
+func Parse(r io.Reader) (*Filter, error) {
+	filter := &Filter{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		filter.words = append(filter.words, normalize(line))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// New creates a filter of the given words.
+func New(words ...string) *Filter {
+	filter := &Filter{}
+	for _, word := range words {
+		if word = strings.TrimSpace(word); word != "" {
+			filter.words = append(filter.words, normalize(word))
+		}
+	}
+	return filter
+}
+
+// Contains reports whether the given text contains any of the words, or a leetspeak variant of them.
+func (f *Filter) Contains(text string) bool {
+	text = normalize(text)
+	for _, word := range f.words {
+		if strings.Contains(text, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize lowercases the text and folds its leetspeak characters.
+func normalize(text string) string {
+	return leetspeak.Replace(strings.ToLower(text))
+}
@@ -0,0 +1,62 @@
+package wordfilter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testWords = `
+# offensive words
+bad
+Ugly
+
+dead
+`
+
+func TestFilter_Contains(t *testing.T) {
+	filter, err := Parse(strings.NewReader(testWords))
+	assert.Nil(t, err)
+
+	tests := map[string]bool{
+		"05bf184":  false,
+		"a0bad12":  true,
+		"BAD0000":  true,
+		"8ad0000":  true,
+		"b4d0000":  true,
+		"ab@d000":  true,
+		"x0ugiy0":  true,
+		"uq1y000":  false,
+		"0dead00":  true,
+		"0d3ad00":  true,
+		"00ba0d0":  false,
+		"ugly":     true,
+		"kduf6s5h": false,
+	}
+	for text, expected := range tests {
+		assert.Equal(t, expected, filter.Contains(text), text)
+	}
+}
+
+func TestNew_ShouldIgnoreEmptyWords(t *testing.T) {
+	filter := New("", " ", "b00")
+	assert.True(t, filter.Contains("0bo00"))
+	assert.False(t, filter.Contains("0b0a0"))
+}
+
+func TestOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	assert.Nil(t, os.WriteFile(path, []byte(testWords), os.ModePerm))
+
+	filter, err := Open(path)
+	assert.Nil(t, err)
+	assert.True(t, filter.Contains("baddeed"))
+}
+
+func TestOpen_ShouldReturnErrorWhenFileNotExists(t *testing.T) {
+	_, err := Open(filepath.Join(t.TempDir(), "words.txt"))
+	assert.Error(t, err)
+}